@@ -0,0 +1,98 @@
+// check.go - one call for all of the results
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"context"
+	"io"
+
+	"github.com/clbanning/mxj"
+)
+
+// Result holds the results of Check and CheckReader.
+type Result struct {
+	Root       string   // the XML root tag
+	Missing    []string // as reported by MissingXMLTags
+	Unknown    []string // as reported by UnknownXMLTags
+	Deprecated []string // the tags in the XML data listed with SetDeprecatedTags
+	Map        mxj.Map  // the decoded XML data, if WithMap(true) is an option
+}
+
+// WithMap causes Check and CheckReader to return the mxj.Map representation of
+// the XML data in Result.Map.  It has no effect for a Validator.
+func WithMap(ok bool) Option {
+	return func(o *options) {
+		o.keepMap = ok
+	}
+}
+
+// Check decodes the XML data once and returns the results of both MissingXMLTags
+// and UnknownXMLTags for the struct 'val'.  The checks use the package settings,
+// modified by 'opts' - e.g., WithTagsToIgnore("config") - for this call only.
+// If a check is terminated with an error, e.g., ErrTruncated, the Result holds the
+// tags found up to that point and is returned with the error.
+//
+//	Example:
+//		res, err := checkxml.Check(b, MyStruct{}, checkxml.WithMap(true))
+//		if err != nil {
+//			...
+//		}
+//		for _, tag := range res.Unknown {
+//			v, _ := res.Map.ValueForPath(res.Root + "." + tag)
+//			...
+//		}
+func Check(b []byte, val interface{}, opts ...Option) (*Result, error) {
+	o := currentOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	m, err := mxj.NewMapXml(skipBOM(b), o.mxjCast)
+	if err != nil {
+		return nil, newParseError(err, b)
+	}
+	return checkResult(m, b, val, o)
+}
+
+// CheckReader is Check for XML data that is read from an io.Reader; see also
+// SetMaxReadSize.
+func CheckReader(r io.Reader, val interface{}, opts ...Option) (*Result, error) {
+	o := currentOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+	cr := newCtxReader(context.Background(), r, o.maxReadSize)
+	m, raw, err := mxj.NewMapXmlReaderRaw(cr, o.mxjCast)
+	if err != nil {
+		return nil, cr.readErr(err)
+	}
+	return checkResult(m, raw, val, o)
+}
+
+// IsValid reports whether the XML data has neither missing nor unknown tags for
+// the struct 'val' - whether MissingXMLTags and UnknownXMLTags would both return
+// no tags.  The XML data is decoded once, and the checks stop at the first missing
+// or unknown tag.  An error decoding the XML data is returned with false.
+func IsValid(b []byte, val interface{}) (bool, error) {
+	o := currentOptions()
+	o.firstFinding = true
+	_, _, missing, unknown, err := checkXMLTags(b, val, o)
+	if err != nil {
+		return false, err
+	}
+	return missing.n == 0 && unknown.n == 0, nil
+}
+
+// checkResult does the work for Check and CheckReader.  If a check is terminated
+// with an error, e.g., ErrTruncated, the Result holds the tags found up to that
+// point.
+func checkResult(m mxj.Map, b []byte, val interface{}, o *options) (*Result, error) {
+	root, _, missing, unknown, err := checkXMLMap(m, b, val, o)
+	res := &Result{Root: root, Missing: missing.tags, Unknown: unknown.tags, Deprecated: unknown.deprecated}
+	if o.keepMap {
+		res.Map = m
+	}
+	return res, err
+}
@@ -0,0 +1,172 @@
+package checkxml
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	type sub struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+	type test struct {
+		ID   string `xml:"id,attr"`
+		Ok   bool   `xml:"ok"`
+		Why  string `xml:"why"`
+		Note sub    `xml:"note"`
+	}
+	data := []byte(`<doc id="1"><ok>true</ok><note lang="en">hi</note><extra>x</extra><other/></doc>`)
+
+	res, err := Check(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(res.Unknown)
+	if res.Root != "doc" || !reflect.DeepEqual(res.Missing, []string{"why"}) ||
+		!reflect.DeepEqual(res.Unknown, []string{"extra", "other"}) || res.Map != nil {
+		t.Fatalf("result: %+v", res)
+	}
+
+	// the options apply to the call only
+	res, err = Check(data, &test{}, WithTagsToIgnore("extra", "other"), WithMembersToIgnore("why"), WithMap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Missing) != 0 || len(res.Unknown) != 0 {
+		t.Fatalf("options: %+v", res)
+	}
+	// the map is intact - the text keys aren't removed
+	if v, _ := res.Map.ValueForPath("doc.note.#text"); v != "hi" {
+		t.Fatalf("map: %v", res.Map)
+	}
+	if tags, _, _ := UnknownXMLTags(data, test{}); len(tags) != 2 {
+		t.Fatal("package settings:", tags)
+	}
+
+	res, err = CheckReader(bytes.NewReader(data), test{}, WithMap(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Root != "doc" || len(res.Missing) != 1 || len(res.Unknown) != 2 || res.Map == nil {
+		t.Fatalf("reader: %+v", res)
+	}
+
+	if _, err = Check([]byte(`<doc>`), test{}); err == nil {
+		t.Fatal("no error")
+	}
+	if _, err = CheckReader(bytes.NewReader([]byte(`<doc>`)), test{}); err == nil {
+		t.Fatal("no reader error")
+	}
+
+	// the tags found before the results are truncated are returned with the error
+	data = []byte(`<doc><note>hi</note><extra>x</extra><other/></doc>`)
+	res, err = Check(data, test{}, WithMaxResults(1))
+	if err != ErrTruncated {
+		t.Fatal("truncated:", err)
+	}
+	if res == nil || len(res.Missing) != 1 || len(res.Unknown) != 1 || res.Root != "doc" {
+		t.Fatalf("truncated: %+v", res)
+	}
+}
+
+func TestIsValid(t *testing.T) {
+	type item struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	type test struct {
+		Title string `xml:"title"`
+		Items []item `xml:"item"`
+	}
+
+	for _, v := range []struct {
+		data  string
+		valid bool
+	}{
+		{`<doc><title>t</title><item id="1"><name>a</name></item><item id="2"><name>b</name></item></doc>`, true},
+		{`<doc><title>t</title><item id="1"><name>a</name></item><item><name>b</name></item></doc>`, false},
+		{`<doc><title>t</title><item id="1"><name>a</name><qty>1</qty></item></doc>`, false},
+		{`<doc>t</doc>`, false},
+	} {
+		ok, err := IsValid([]byte(v.data), test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != v.valid {
+			t.Fatal("valid:", ok, v.data)
+		}
+	}
+
+	// the checks stop at the first finding
+	data := []byte(`<doc><item><name>a</name><qty>1</qty></item><other/></doc>`)
+	o := currentOptions()
+	o.firstFinding = true
+	_, _, missing, unknown, err := checkXMLTags(data, test{}, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if missing.n != 1 || len(missing.tags) != 0 || unknown.n != 0 {
+		t.Fatal("first finding:", missing.n, missing.tags, unknown.n)
+	}
+
+	if _, err = IsValid([]byte(`<doc>`), test{}); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestSetDeprecatedTags(t *testing.T) {
+	type item struct {
+		Name string `xml:"name"`
+		Code string `xml:"code"`
+	}
+	type test struct {
+		Title  string `xml:"title"`
+		Old    string `xml:"old"`
+		Items  []item `xml:"item"`
+		Legacy string `xml:"legacy"`
+	}
+	data := []byte(`<doc><title>t</title><fax>1</fax><item><name>a</name><sku>1</sku></item><item><name>b</name><sku>2</sku></item><legacy>l</legacy><extra/></doc>`)
+
+	res, err := Check(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(res.Unknown)
+	if len(res.Deprecated) != 0 || !reflect.DeepEqual(res.Unknown, []string{"extra", "fax", "item.sku", "item.sku"}) {
+		t.Fatal("not deprecated:", res.Deprecated, res.Unknown)
+	}
+
+	SetDeprecatedTags("fax", "*.sku", "old", "item.code", "legacy")
+	defer SetDeprecatedTags()
+	res, err = Check(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(res.Deprecated)
+	if !reflect.DeepEqual(res.Deprecated, []string{"fax", "item.sku", "legacy"}) {
+		t.Fatal("deprecated:", res.Deprecated)
+	}
+	if !reflect.DeepEqual(res.Unknown, []string{"extra"}) {
+		t.Fatal("unknown:", res.Unknown)
+	}
+	// absent deprecated members aren't missing
+	if len(res.Missing) != 0 {
+		t.Fatal("missing:", res.Missing)
+	}
+
+	tags, root, err := DeprecatedXMLTags(data, test{})
+	if err != nil || root != "doc" || len(tags) != 3 {
+		t.Fatal("DeprecatedXMLTags:", tags, root, err)
+	}
+	if tags, _, _ = UnknownXMLTags(data, test{}); len(tags) != 1 {
+		t.Fatal("UnknownXMLTags:", tags)
+	}
+
+	v := NewValidator(reflect.TypeOf(test{}), WithDeprecatedTags())
+	if _, u, _, _ := v.Validate(data); len(u) != 4 {
+		t.Fatal("validator:", u)
+	}
+}
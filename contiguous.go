@@ -0,0 +1,134 @@
+// contiguous.go - identify repeated XML data elements that are interleaved
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+
+	"github.com/clbanning/mxj"
+)
+
+// CheckContiguous returns a slice of the dot-notation tags of the struct members
+// of type slice whose repeated XML data elements are not contiguous - another
+// element occurs between two of them.  The encoding/xml Unmarshal function, like
+// mxj.NewMapXml, collects the repeated elements regardless of any interleaving;
+// this is only of interest if a schema requires them to be contiguous.  Each tag is
+// reported once.  Elements that don't correspond to a struct member, attributes,
+// and any tags listed with SetTagsToIgnore are not considered.  If the XML data
+// can't be decoded, nil is returned.
+//
+//	Example:
+//		type doc struct {
+//			Items []string `xml:"item"`
+//			Note  string   `xml:"note"`
+//		}
+//		data := []byte(`<doc><item>1</item><note>x</note><item>2</item></doc>`)
+//		fmt.Println(CheckContiguous(data, doc{})) // prints: [item]
+//
+// NOTE: as with CheckElementOrder, the XML data is decoded using mxj.NewMapXmlSeq,
+// which preserves the sequence of the elements as "#seq" keys; the element sequence
+// isn't available from mxj.NewMapXml.
+// (See github.com/clbanning/mxj documentation of mxj.NewMapXmlSeq.)
+func CheckContiguous(b []byte, val interface{}) []string {
+	m, err := mxj.NewMapXmlSeq(skipBOM(b))
+	if err != nil {
+		return nil
+	}
+	// strip off the root value
+	var v interface{}
+	for _, v = range m {
+		break
+	}
+
+	var s tagList
+	checkContiguous(v, reflect.TypeOf(val), &s, "", make(map[string]bool))
+	return s.tags
+}
+
+// checkContiguous reports the slice members of the struct type 'typ' whose elements
+// are interleaved with other child elements of 'mv'; 'seen' prevents reporting a
+// tag more than once.
+func checkContiguous(mv interface{}, typ reflect.Type, s *tagList, key string, seen map[string]bool) {
+	if s.tooDeep(key) {
+		return
+	}
+	if typ == nil {
+		return
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isLeafType(typ) {
+		return
+	}
+	if typ.Kind() == reflect.Slice {
+		list, ok := mv.([]interface{})
+		if !ok {
+			list = []interface{}{mv}
+		}
+		for _, lv := range list {
+			if s.done {
+				return
+			}
+			checkContiguous(lv, typ.Elem(), s, key, seen)
+		}
+		return
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	mm, ok := mv.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	o := s.opt()
+	ts := getTypeSpec(typ, o.attrPrefix)
+	// the key of the previous element and the keys of the element runs that
+	// have been ended by another element
+	var prev string
+	ended := make(map[string]bool)
+	var tkey string
+	var spec *fieldSpec
+	for _, e := range seqElems(mm) {
+		if s.done {
+			return
+		}
+		if key == "" {
+			tkey = e.key
+		} else {
+			tkey = key + "." + e.key
+		}
+		for _, sk := range o.skiptags {
+			if tkey == sk || globMatch(sk, tkey) {
+				goto next
+			}
+		}
+		if prev != "" && prev != e.key {
+			ended[prev] = true
+		}
+		prev = e.key
+		spec, ok = ts.keys[e.key]
+		if !ok || spec.skip || len(spec.tag) > 1 {
+			continue
+		}
+		if ended[e.key] && isSliceMember(typ.FieldByIndex(spec.index).Type) && !seen[tkey] {
+			seen[tkey] = true
+			s.add(tkey, typ)
+		}
+		checkContiguous(e.val, typ.FieldByIndex(spec.index).Type, s, tkey, seen)
+	next:
+	}
+}
+
+// isSliceMember reports whether a member of type 'typ' collects repeated elements;
+// a []byte member, or a slice type that decodes itself, see isLeafType, doesn't.
+func isSliceMember(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8 && !isLeafType(typ)
+}
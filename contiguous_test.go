@@ -0,0 +1,45 @@
+package checkxml
+
+import (
+	"testing"
+)
+
+func TestCheckContiguous(t *testing.T) {
+	type item struct {
+		Name string   `xml:"name"`
+		Tags []string `xml:"tag"`
+	}
+	type test struct {
+		ID    string `xml:"id,attr"`
+		Items []item `xml:"item"`
+		Notes []string
+		Note  string `xml:"note"`
+	}
+
+	data := []byte(`<doc id="1"><item><name>x</name><tag>a</tag><tag>b</tag></item><item><name>y</name></item><note>n</note><Notes>1</Notes><Notes>2</Notes></doc>`)
+	if tags := CheckContiguous(data, test{}); len(tags) != 0 {
+		t.Fatal("contiguous:", tags)
+	}
+
+	// interleaved at both levels; <extra> isn't a member but still interleaves
+	data = []byte(`<doc id="1"><item><tag>a</tag><name>x</name><tag>b</tag></item><note>n</note><item><name>y</name><tag>c</tag></item><Notes>1</Notes><extra/><Notes>2</Notes></doc>`)
+	tags := CheckContiguous(data, test{})
+	if len(tags) != 3 || tags[0] != "item.tag" || tags[1] != "item" || tags[2] != "Notes" {
+		t.Fatal("interleaved:", tags)
+	}
+	// the data is still complete
+	if mems, _, _ := MissingXMLTags(data, test{}); len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+
+	SetTagsToIgnore("extra")
+	defer SetTagsToIgnore()
+	tags = CheckContiguous(data, test{})
+	if len(tags) != 2 || tags[1] != "item" {
+		t.Fatal("ignore:", tags)
+	}
+
+	if tags = CheckContiguous([]byte(`<doc><item>`), test{}); tags != nil {
+		t.Fatal("bad data:", tags)
+	}
+}
@@ -0,0 +1,151 @@
+// cross.go - cross-check MissingXMLTags with encoding/xml Unmarshal
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// Cross is a dry-run that checks the MissingXMLTags result against what the
+// encoding/xml Unmarshal function actually does.  The XML data is unmarshaled into
+// a new value of the type of 'val' and the dot-notation tags of the struct members
+// for which the prediction and the result disagree are returned - either the member
+// was predicted to be missing but was set, or it was predicted to be present but
+// was left with its zero value.  An empty result means MissingXMLTags can be relied
+// upon for the struct definition.  'val' is a struct, or a pointer to or slice of a
+// struct, as for MissingXMLTags; an error is returned if it isn't a struct
+// definition.  Any error from Unmarshal is returned.
+//
+// The prediction is made with all members checked - as if IgnoreOmitemptyTag(false)
+// had been called and without the SetMembersToIgnore list.  Members with a
+// `xml:"elem>sub"` tag path are reported using the full path, "elem.sub".
+//
+// NOTE: since a numeric or bool member can be set to its zero value from the XML
+// data, e.g., <n>0</n>, such members are only reported if they were predicted to
+// be missing and were set.  Slice members are compared as a whole; the members of
+// their elements are not cross-checked.
+func Cross(b []byte, val interface{}) ([]string, string, error) {
+	typ := reflect.TypeOf(val)
+	if typ == nil || memberType(typ).Kind() != reflect.Struct || isLeafType(memberType(typ)) {
+		return nil, "", fmt.Errorf("not a struct definition: %T", val)
+	}
+	typ = memberType(typ)
+	rv := reflect.New(typ)
+	if err := xml.Unmarshal(b, rv.Interface()); err != nil {
+		return nil, "", newParseError(err, b)
+	}
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip off the root value
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	// predict with every member checked
+	o := *currentOptions()
+	o.skipmembers = nil
+	o.omitemptyOK = false
+	o.emptyAsMissing = false
+	o.ignoreAttrs = false
+	o.maxResults = 0
+	s := tagList{opts: &o}
+	checkMembers(v, rv, &s, "")
+	if s.err != nil {
+		return nil, root, s.err
+	}
+	missing := make(map[string]bool, len(s.tags))
+	for _, t := range s.tags {
+		missing[t] = true
+	}
+
+	var diff []string
+	crossCheck(v, rv.Elem(), missing, &o, "", &diff)
+	return diff, root, nil
+}
+
+// crossCheck compares the 'missing' prediction for the members of the struct value
+// 'val', which has been unmarshaled from the XML data, with the values that were set.
+func crossCheck(mv interface{}, val reflect.Value, missing map[string]bool, o *options, cmem string, diff *[]string) {
+	mm, _ := mv.(map[string]interface{})
+	join := func(k string) string {
+		if cmem == "" {
+			return k
+		}
+		return cmem + "." + k
+	}
+
+	for _, field := range getTypeSpec(val.Type(), o.attrPrefix).fields {
+		if field.skip || val.Type().FieldByIndex(field.index).Type.Kind() == reflect.Interface {
+			continue
+		}
+		fn := field.key
+		if field.chardata {
+			fn = textKey
+		}
+		tkey := join(fn)
+		predicted := !missing[tkey]
+
+		// the XML data value, following any tag path
+		dv, ok := mm[fn]
+		if !ok && field.chardata && mm == nil {
+			dv = mv // a simple element
+		}
+		path := tkey
+		if len(field.tag) > 1 {
+			path = join(strings.Join(field.tag, "."))
+			for _, k := range field.tag[1:] {
+				dm, _ := dv.(map[string]interface{})
+				dv = dm[k]
+			}
+		}
+
+		fval := field.value(val)
+		ftyp := fval.Type()
+		if ftyp.Kind() == reflect.Ptr {
+			ftyp = ftyp.Elem()
+		}
+		// descend into struct members
+		if ftyp.Kind() == reflect.Struct && !isLeafType(ftyp) && len(field.tag) == 1 {
+			if fval.Kind() == reflect.Ptr {
+				if fval.IsNil() {
+					if predicted {
+						*diff = append(*diff, path)
+					}
+					continue
+				}
+				fval = fval.Elem()
+			}
+			crossCheck(dv, fval, missing, o, tkey, diff)
+			continue
+		}
+
+		set := !fval.IsZero()
+		switch {
+		case !predicted && set:
+			*diff = append(*diff, path)
+		case predicted && !set:
+			switch ftyp.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+				reflect.Float32, reflect.Float64:
+				continue // the zero value may have been decoded
+			}
+			if dv == nil || !isEmptyValue(dv) {
+				*diff = append(*diff, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package checkxml
+
+import (
+	"testing"
+)
+
+func TestCross(t *testing.T) {
+	type sub struct {
+		Lang string `xml:"lang,attr,omitempty"`
+		Text string `xml:",chardata"`
+	}
+	type test struct {
+		ID     string `xml:"id,attr"`
+		Note   string `xml:"note,omitempty"`
+		Count  int    `xml:"count"`
+		Sub    sub    `xml:"sub"`
+		Ptr    *sub   `xml:"ptr"`
+		Author string `xml:"meta>author"`
+		Date   string `xml:"meta>date"`
+	}
+
+	// prediction and Unmarshal agree
+	data := []byte(`<doc id="1"><note>x</note><count>0</count><sub lang="en">hi</sub><ptr>there</ptr><meta><author>me</author><date>now</date></meta></doc>`)
+	diff, root, err := Cross(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(diff) != 0 {
+		t.Fatal("agree:", root, diff)
+	}
+	diff, _, err = Cross([]byte(`<doc/>`), &test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 0 {
+		t.Fatal("empty:", diff)
+	}
+
+	// the wrapper <meta> is present, so "meta>date" is predicted to be set
+	data = []byte(`<doc id="1"><meta><author>me</author></meta></doc>`)
+	diff, _, err = Cross(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 1 || diff[0] != "meta.date" {
+		t.Fatal("path:", diff)
+	}
+
+	// ",innerxml" and ",any" members aren't known to MissingXMLTags
+	type raw struct {
+		Name  string `xml:"name"`
+		Inner string `xml:",innerxml"`
+		Any   string `xml:",any"`
+	}
+	diff, _, err = Cross([]byte(`<doc><name>x</name><other>y</other></doc>`), raw{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 2 || diff[0] != "Inner" || diff[1] != "Any" {
+		t.Fatal("innerxml:", diff)
+	}
+
+	if _, _, err = Cross([]byte(`<doc>`), test{}); err == nil {
+		t.Fatal("no error")
+	}
+
+	// a slice of a struct is checked as the struct; other values are an error
+	diff, _, err = Cross(data, []test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 1 || diff[0] != "meta.date" {
+		t.Fatal("slice:", diff)
+	}
+	for _, val := range []interface{}{nil, "doc", []string{}} {
+		if _, _, err = Cross(data, val); err == nil {
+			t.Fatalf("no error: %T", val)
+		}
+	}
+}
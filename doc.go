@@ -41,8 +41,17 @@ Unmarshal function for the specified struct definition.
 	// result: [elem2.notes elem4]
 	// root: doc
 
-NOTE: this package is dependent upon github.com/clbanning/mxj.
-
-NOTE: function MissingXMLTags DOES NOT support recursive structs
+The package settings - SetTagsToIgnore, IgnoreOmitemptyTag, etc. - are global. A Validator,
+see NewValidator, checks XML data against a struct type with its own settings, so it can be
+used concurrently with different settings. The Check function returns both the missing and
+the unknown tags, decoding the XML data once, with the package settings modified by options
+for the call.
+
+As with encoding/xml, the members of an embedded struct are checked as members of the
+embedding struct; any xml tag of the embedded struct, other than "-", is ignored.
+
+NOTE: this package is dependent upon github.com/clbanning/mxj. The MissingXMLTagsStd and
+UnknownXMLTagsStd functions decode the XML data using only the encoding/xml package. The
+CheckElementOrder function uses mxj.NewMapXmlSeq to preserve the sequence of the elements.
 */
 package checkxml
@@ -0,0 +1,101 @@
+// duplicates.go - identify repeated XML data elements for non-slice members
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+
+	"github.com/clbanning/mxj"
+)
+
+// DuplicateElementTags returns the dot-notation tags of the elements that occur
+// more than once in the XML data within the same parent element although the
+// corresponding member of 'val', which is of type struct, isn't a slice; e.g.,
+// <doc><name>a</name><name>b</name></doc> for a `xml:"name"` member of type string.
+// The encoding/xml Unmarshal function decodes each of the elements into the same
+// member, so, for a simple member, only the last value is kept.  Each tag is reported
+// once.  Elements that don't correspond to a struct member are not considered; see
+// UnknownXMLTags.  If the XML data can't be decoded, nil is returned.
+//
+// NOTE: mxj.NewMapXml decodes repeated elements as a []interface{} value, which is
+// how they're identified; the elements needn't be contiguous.
+func DuplicateElementTags(b []byte, val interface{}) []string {
+	var s tagList
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	for _, v = range m {
+		break
+	}
+	checkDuplicates(v, reflect.ValueOf(val), &s, "", make(map[string]bool))
+	return s.tags
+}
+
+// checkDuplicates walks the struct value 'val' and the mxj.Map value 'mv' in
+// parallel, as checkMembers does; 'seen' prevents reporting a tag more than once.
+func checkDuplicates(mv interface{}, val reflect.Value, s *tagList, cmem string, seen map[string]bool) {
+	if s.tooDeep(cmem) {
+		return
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() || isLeafType(val.Type()) {
+		return
+	}
+	typ := val.Type()
+	if typ.Kind() == reflect.Slice {
+		list, ok := mv.([]interface{})
+		if !ok {
+			list = []interface{}{mv}
+		}
+		sval := reflect.New(typ.Elem())
+		for _, v := range list {
+			checkDuplicates(v, sval, s, cmem, seen)
+		}
+		return
+	}
+	mm, ok := mv.(map[string]interface{})
+	if typ.Kind() != reflect.Struct || !ok {
+		return
+	}
+
+	o := s.opt()
+	for _, field := range getTypeSpec(typ, o.attrPrefix).fields {
+		if s.done {
+			return
+		}
+		if field.skip || field.attr || field.chardata || len(field.tag) > 1 {
+			continue
+		}
+		v, ok := mm[field.key]
+		if !ok {
+			continue
+		}
+		tkey := field.key
+		if cmem != "" {
+			tkey = cmem + "." + field.key
+		}
+		ftyp := typ.FieldByIndex(field.index).Type
+		list, isList := v.([]interface{})
+		if !isList || isSliceMember(ftyp) {
+			checkDuplicates(v, field.value(val), s, tkey, seen)
+			continue
+		}
+		if !seen[tkey] {
+			seen[tkey] = true
+			s.add(tkey, typ)
+		}
+		for _, lv := range list {
+			checkDuplicates(lv, field.value(val), s, tkey, seen)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicateElementTags(t *testing.T) {
+	type sub struct {
+		Code string `xml:"code"`
+	}
+	type item struct {
+		Name  string `xml:"name"`
+		Price int    `xml:"price"`
+	}
+	type test struct {
+		ID    string `xml:"id,attr"`
+		Title string `xml:"title"`
+		Sub   *sub   `xml:"sub"`
+		Items []item `xml:"item"`
+		Data  []byte `xml:"data"`
+	}
+
+	data := []byte(`<doc id="1"><title>t</title><sub><code>a</code></sub><item><name>x</name></item><item><name>y</name></item><data>d</data></doc>`)
+	if tags := DuplicateElementTags(data, test{}); len(tags) != 0 {
+		t.Fatal("no duplicates:", tags)
+	}
+
+	data = []byte(`<doc id="1">
+		<title>t</title>
+		<sub><code>a</code></sub>
+		<item><name>x</name><price>1</price><name>z</name></item>
+		<title>u</title>
+		<item><name>y</name></item>
+		<sub><code>b</code><code>c</code></sub>
+		<data>d</data><data>e</data>
+		<other/><other/>
+	</doc>`)
+	tags := DuplicateElementTags(data, test{})
+	want := []string{"title", "sub", "sub.code", "item.name", "data"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("duplicates:", tags)
+	}
+
+	if tags = DuplicateElementTags([]byte(`<doc>`), test{}); tags != nil {
+		t.Fatal("bad data:", tags)
+	}
+}
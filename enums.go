@@ -0,0 +1,139 @@
+// enums.go - check the XML data values against the enumerations in the struct tags
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// CheckEnums returns the dot-notation XML tags of the attribute and element values
+// in the XML data that aren't one of the values enumerated in the "checkxml" tag of
+// the corresponding member of 'val', which is of type struct; e.g.,
+//
+//	Status string `xml:"status,attr" checkxml:"enum=open|closed|pending"`
+//
+// The values are compared after trimming white space.  If the XML data is decoded
+// with casting, see SetMxjCast, the float64 and bool values are compared in their
+// shortest string form; e.g., <n>1.0</n> matches "enum=1|2".  For a slice member
+// each of the values is checked; each tag is reported once.  Members without an
+// enumeration and values that don't correspond to a member aren't checked.  If the
+// XML data can't be decoded, nil is returned.
+func CheckEnums(b []byte, val interface{}) []string {
+	var s tagList
+	m, err := mxj.NewMapXml(skipBOM(b), s.opt().mxjCast)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	for _, v = range m {
+		break
+	}
+	checkEnums(v, reflect.ValueOf(val), &s, "", make(map[string]bool))
+	return s.tags
+}
+
+// checkEnums walks the struct value 'val' and the mxj.Map value 'mv' in parallel,
+// as checkMembers does; 'seen' prevents reporting a tag more than once.
+func checkEnums(mv interface{}, val reflect.Value, s *tagList, cmem string, seen map[string]bool) {
+	if s.tooDeep(cmem) {
+		return
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() || isLeafType(val.Type()) {
+		return
+	}
+	typ := val.Type()
+	if typ.Kind() == reflect.Slice {
+		list, ok := mv.([]interface{})
+		if !ok {
+			list = []interface{}{mv}
+		}
+		sval := reflect.New(typ.Elem())
+		for _, v := range list {
+			checkEnums(v, sval, s, cmem, seen)
+		}
+		return
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+
+	o := s.opt()
+	mm, _ := mv.(map[string]interface{})
+	for _, field := range getTypeSpec(typ, o.attrPrefix).fields {
+		if s.done {
+			return
+		}
+		if field.skip || len(field.tag) > 1 {
+			continue
+		}
+		fn := field.key
+		if field.chardata {
+			fn = textKey
+		}
+		v, ok := mm[fn]
+		if !ok && field.chardata && mm == nil {
+			v, ok = mv, mv != nil // a simple element
+		}
+		if !ok {
+			continue
+		}
+		tkey := fn
+		if cmem != "" {
+			tkey = cmem + "." + fn
+		}
+		if len(field.enum) == 0 {
+			checkEnums(v, field.value(val), s, tkey, seen)
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		for _, lv := range list {
+			if !seen[tkey] && !inEnum(enumValue(lv), field.enum) {
+				seen[tkey] = true
+				s.add(tkey, typ)
+			}
+		}
+	}
+}
+
+// enumValue returns the mxj.Map value 'v' - or the character data of an element
+// with attributes - as a string to compare with an enumeration.
+func enumValue(v interface{}) string {
+	if mm, ok := v.(map[string]interface{}); ok {
+		v = mm[textKey]
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// inEnum reports whether 'v' is one of the values in 'enum'.
+func inEnum(v string, enum []string) bool {
+	for _, e := range enum {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package checkxml
+
+import (
+	"testing"
+)
+
+func TestCheckEnums(t *testing.T) {
+	type item struct {
+		Status string   `xml:"status,attr" checkxml:"enum=open|closed|pending"`
+		Sizes  []string `xml:"size" checkxml:"enum=S|M|L"`
+		Level  int      `xml:"level" checkxml:"enum=1|2|3"`
+		Note   string   `xml:"note"`
+	}
+	type test struct {
+		Items []item `xml:"item"`
+		Kind  string `xml:"kind" checkxml:"enum=a|b"`
+	}
+
+	data := []byte(`<doc>
+		<item status="open"><size>S</size><size> L </size><level>2</level><note>x</note></item>
+		<item status="pending"><level>3</level></item>
+		<kind>b</kind>
+	</doc>`)
+	if tags := CheckEnums(data, test{}); len(tags) != 0 {
+		t.Fatal("in enums:", tags)
+	}
+
+	data = []byte(`<doc>
+		<item status="open"><size>S</size><size>XL</size><level>2</level></item>
+		<item status="reopened"><size>XXL</size><level>1.0</level></item>
+		<kind>c</kind>
+	</doc>`)
+	tags := CheckEnums(data, test{})
+	want := []string{"item.size", "item.-status", "item.level", "kind"}
+	if len(tags) != len(want) {
+		t.Fatal("not in enums:", tags)
+	}
+	for i, tag := range tags {
+		if tag != want[i] {
+			t.Fatal("not in enums:", tags)
+		}
+	}
+
+	// cast values are compared in their shortest form
+	SetMxjCast(true)
+	defer SetMxjCast(false)
+	tags = CheckEnums(data, test{})
+	if len(tags) != 3 || tags[2] != "kind" {
+		t.Fatal("cast:", tags)
+	}
+
+	if tags = CheckEnums([]byte(`<doc>`), test{}); tags != nil {
+		t.Fatal("bad data:", tags)
+	}
+}
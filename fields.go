@@ -0,0 +1,308 @@
+// fields.go - parse and cache the xml tag specs of struct members
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"encoding"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldSpec is the xml tag information for an exported struct member.
+type fieldSpec struct {
+	index     []int    // index sequence of the member in the struct; see value
+	name      string   // member name, attrPrefix prepended if an attribute
+	tag       []string // tag may be a path, attrPrefix prepended to tag[0] if an attribute
+	key       string   // mxj.Map key for the member - tag[0] or, if no tag, name
+	omitempty bool
+	attr      bool
+	chardata  bool     // member is the element's character data, ",chardata" or ",cdata"; key is textKey
+	skip      bool     // xml tag is "-"
+	enum      []string // allowed values from a `checkxml:"enum=a|b"` tag; see CheckEnums
+}
+
+// typeSpec holds the fieldSpecs for a struct type, in sequence and keyed
+// by their mxj.Map key.  Members with ",chardata" tags and tag paths are
+// not in keys; see wrappers.
+type typeSpec struct {
+	fields     []*fieldSpec
+	keys       map[string]*fieldSpec
+	unexported map[string]bool // keys that match unexported members
+	chardata   bool            // there is a ",chardata" member
+	xmlName    string          // local name in the XMLName member tag, if any
+	xmlNS      string          // namespace in the XMLName member tag, if any
+	flat       bool            // no member is checked below its own element - see isFlatType
+	hidden     []*fieldSpec    // promoted members hidden by a shallower member; see CheckStructTags
+
+	// wrapper elements, "elem", of `xml:"elem>sub"` member tags; the fields
+	// and keys of a wrapper spec are the members whose tag paths pass through it
+	wrappers map[string]*typeSpec
+}
+
+// specCache is a map[specKey]*typeSpec; the parsed specs depend only on the
+// struct type and the attribute prefix, not on any of the ignore lists or flags,
+// so they can be shared.
+var specCache sync.Map
+
+type specKey struct {
+	typ    reflect.Type
+	prefix string
+}
+
+// getTypeSpec returns the typeSpec for the struct type 'typ' and attribute prefix
+// 'prefix', parsing the struct member tags on first use.
+func getTypeSpec(typ reflect.Type, prefix string) *typeSpec {
+	key := specKey{typ, prefix}
+	if ts, ok := specCache.Load(key); ok {
+		return ts.(*typeSpec)
+	}
+	ts, _ := specCache.LoadOrStore(key, newTypeSpec(typ, key.prefix))
+	return ts.(*typeSpec)
+}
+
+// newTypeSpec parses the member tags of the struct type 'typ'; 'prefix' is
+// prepended to the keys of attribute members.
+func newTypeSpec(typ reflect.Type, prefix string) *typeSpec {
+	fieldCnt := typ.NumField()
+	ts := &typeSpec{
+		fields: make([]*fieldSpec, 0, fieldCnt), // use a list so members are in sequence
+		keys:   make(map[string]*fieldSpec, fieldCnt),
+	}
+	ts.addFields(typ, prefix, nil, map[reflect.Type]bool{})
+	ts.flat = ts.wrappers == nil
+	for _, fs := range ts.fields {
+		if !fs.skip && !isFlatType(typ.FieldByIndex(fs.index).Type) {
+			ts.flat = false
+		}
+	}
+	return ts
+}
+
+// addFields adds the members of the struct type 'typ' to 'ts'; 'index' is the
+// index sequence of 'typ' if it's an embedded struct.  The members of an embedded
+// struct are promoted, as encoding/xml does, whether or not it has an xml tag,
+// unless the tag is "-".  'visited' holds the embedded struct types to handle
+// recursive definitions.
+func (ts *typeSpec) addFields(typ reflect.Type, prefix string, index []int, visited map[reflect.Type]bool) {
+	if visited[typ] {
+		return
+	}
+	visited[typ] = true
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		idx := append(append(make([]int, 0, len(index)+1), index...), i)
+		tags := strings.Split(field.Tag.Get("xml"), ",")
+		// encoding/xml ignores the tag of an embedded struct, other than "-".
+		if field.Anonymous && tags[0] != "-" {
+			if etyp := embeddedStruct(field.Type); etyp != nil {
+				ts.addFields(etyp, prefix, idx, visited)
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 {
+			// field is NOT exported - just note its name in case
+			// ReportUnexportedAsKnown has been called.
+			if ts.unexported == nil {
+				ts.unexported = make(map[string]bool)
+			}
+			ts.unexported[field.Name] = true
+			continue
+		}
+		// Ignore xml.Name type fields - they don't appear in the map mm.
+		// The root label is handed in as "key" in the initial call.
+		if field.Type.Name() == "Name" && field.Type.PkgPath() == "encoding/xml" {
+			// Keep the XMLName tag, "[namespace ]name", for SetCheckRoot;
+			// the struct's own XMLName has precedence over an embedded one.
+			if field.Name == "XMLName" && (index == nil || ts.xmlName == "") {
+				ts.xmlNS, ts.xmlName = splitNamespace(tags[0])
+			}
+			continue
+		}
+		// Get tag and attr info from member spec
+		// A go xml tag may be a single label, e.g., "elem",
+		// or it may be a path to a subelement, e.g., "elem>sub>stuff",
+		// see: https://golang.org/pkg/encoding/xml/#example_Unmarshal.
+		fs := &fieldSpec{index: idx, name: field.Name}
+		// The mxj.Map keys are the local names of the elements, so any namespace,
+		// "namespace name", is dropped.
+		_, name := splitNamespace(tags[0])
+		fs.tag = strings.Split(name, ">")
+		// Fields with "-" might, validly, be there
+		// so allow the field name to be included.
+		if fs.tag[0] == "-" {
+			fs.skip = true
+			fs.tag = []string{""}
+		}
+		// Scan rest of tags for "omitempty" and "attr".
+		for _, v := range tags[1:] {
+			switch v {
+			case "omitempty":
+				fs.omitempty = true
+			case "attr":
+				fs.attr = true
+			case "chardata", "cdata":
+				// encoding/xml decodes the character data, including CDATA
+				// sections, into a ",cdata" member as it does for ",chardata"
+				fs.chardata = true
+			}
+		}
+		// The checkxml tag holds options of this package, e.g., "enum=a|b|c".
+		for _, v := range strings.Split(field.Tag.Get("checkxml"), ",") {
+			if strings.HasPrefix(v, "enum=") {
+				fs.enum = strings.Split(v[len("enum="):], "|")
+			}
+		}
+		// If attr==true then the mm key will be prepended with attrPrefix, "-",
+		// so the Field name and the 'tag' value must be prepended with the
+		// prefix to match the decoded value.
+		// NOTE: the xml decoder requires that elem/attr tags match exactly
+		// the public member name or its xml tag label; unlike json decoder
+		// there is no coersion of lower case element tags to public
+		// member names.
+		if fs.attr {
+			fs.name = prefix + fs.name
+			if fs.tag[0] != "" {
+				fs.tag[0] = prefix + fs.tag[0]
+			}
+		}
+		if fs.tag[0] != "" {
+			fs.key = fs.tag[0]
+		} else {
+			fs.key = fs.name
+		}
+		// The character data is decoded by mxj with the text key, "#text",
+		// whatever the member name or tag.
+		if fs.chardata {
+			ts.fields = append(ts.fields, fs)
+			ts.chardata = true
+			continue
+		}
+		if len(fs.tag) > 1 {
+			ts.fields = append(ts.fields, fs)
+			ts.addPath(fs, fs.tag)
+			continue
+		}
+		// A promoted member is hidden by a member with the same key at a
+		// shallower depth, as the Go selector rules have it.
+		if prev, ok := ts.keys[fs.key]; ok {
+			if len(prev.index) < len(fs.index) {
+				ts.hidden = append(ts.hidden, fs)
+				continue
+			}
+			if len(prev.index) > len(fs.index) {
+				ts.removeField(prev)
+				ts.hidden = append(ts.hidden, prev)
+			}
+		}
+		ts.fields = append(ts.fields, fs)
+		ts.keys[fs.key] = fs
+	}
+}
+
+// removeField removes the member 'fs', hidden by a shallower member, from the
+// fields of 'ts'.
+func (ts *typeSpec) removeField(fs *fieldSpec) {
+	for i, v := range ts.fields {
+		if v == fs {
+			ts.fields = append(ts.fields[:i], ts.fields[i+1:]...)
+			return
+		}
+	}
+}
+
+// embeddedStruct returns the struct type of the embedded member type 'typ' - a
+// struct or a pointer to a struct - or nil if its members aren't promoted.
+func embeddedStruct(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || isLeafType(typ) {
+		return nil
+	}
+	if typ.Name() == "Name" && typ.PkgPath() == "encoding/xml" {
+		return nil
+	}
+	return typ
+}
+
+// value returns the member 'fs' of the struct value 'val', or the zero value of
+// the member type if it's promoted from a nil embedded struct pointer.
+func (fs *fieldSpec) value(val reflect.Value) reflect.Value {
+	root := val.Type()
+	for i, x := range fs.index {
+		if i > 0 && val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Zero(root.FieldByIndex(fs.index).Type)
+			}
+			val = val.Elem()
+		}
+		val = val.Field(x)
+	}
+	return val
+}
+
+// splitNamespace splits the xml tag name "[namespace ]name" into its namespace
+// and name.
+func splitNamespace(tag string) (string, string) {
+	if i := strings.Index(tag, " "); i >= 0 {
+		return tag[:i], tag[i+1:]
+	}
+	return "", tag
+}
+
+// addPath adds the member 'fs' with the xml tag path 'path', e.g., "elem>sub>stuff",
+// to the wrapper specs for the path elements.
+func (ts *typeSpec) addPath(fs *fieldSpec, path []string) {
+	if ts.wrappers == nil {
+		ts.wrappers = make(map[string]*typeSpec)
+	}
+	ws, ok := ts.wrappers[path[0]]
+	if !ok {
+		ws = &typeSpec{keys: make(map[string]*fieldSpec)}
+		ts.wrappers[path[0]] = ws
+	}
+	ws.fields = append(ws.fields, fs)
+	if len(path) > 2 {
+		ws.addPath(fs, path[1:])
+		return
+	}
+	ws.keys[path[1]] = fs
+}
+
+// isFlatType reports whether the XML data for a member of type 'typ' has nothing
+// below its own element to check - i.e., it isn't a struct, or a pointer to or
+// list of structs, whose members are checked.  For a struct with only such members
+// checkMembers and checkAllTags needn't descend into the members' XML data.
+func isFlatType(typ reflect.Type) bool {
+	typ = memberType(typ)
+	return typ.Kind() != reflect.Struct || isLeafType(typ)
+}
+
+// memberType returns the type whose members are decoded for a value of type 'typ'
+// - the element type of any pointer and slice types, including named types such as
+// "type Items []Item".  A type that is decoded by an UnmarshalText or UnmarshalXML
+// method, see isLeafType, is returned as is, even if it's a slice.
+func memberType(typ reflect.Type) reflect.Type {
+	for (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice) && !isLeafType(typ) {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	xmlUnmarshalerType  = reflect.TypeOf((*xml.Unmarshaler)(nil)).Elem()
+)
+
+// isLeafType reports whether values of type 'typ' are decoded by an UnmarshalText
+// or UnmarshalXML method - e.g., time.Time - rather than by the xml decoder setting
+// their members; the XML data for such values is not checked against the members.
+func isLeafType(typ reflect.Type) bool {
+	ptyp := reflect.PtrTo(typ)
+	return ptyp.Implements(textUnmarshalerType) || ptyp.Implements(xmlUnmarshalerType)
+}
@@ -0,0 +1,507 @@
+package checkxml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clbanning/mxj"
+)
+
+func TestGetTypeSpec(t *testing.T) {
+	type test struct {
+		XMLName struct{} `xml:"doc"`
+		ID      string   `xml:"id,attr,omitempty"`
+		Name    string   `xml:"name"`
+		Skip    string   `xml:"-"`
+		Path    string   `xml:"a>b"`
+		Plain   string
+		hidden  string
+	}
+	typ := reflect.TypeOf(test{})
+
+	var wg sync.WaitGroup
+	specs := make([]*typeSpec, 10)
+	for i := range specs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			specs[i] = getTypeSpec(typ, "-")
+		}(i)
+	}
+	wg.Wait()
+	for _, ts := range specs[1:] {
+		if ts != specs[0] {
+			t.Fatal("typeSpec not cached")
+		}
+	}
+
+	check := map[string]string{"XMLName": "doc", "-ID": "-id", "Name": "name", "Skip": "Skip", "Path": "a", "Plain": "Plain"}
+	ts := specs[0]
+	if len(ts.fields) != len(check) {
+		t.Fatalf("fields: %d", len(ts.fields))
+	}
+	for _, fs := range ts.fields {
+		if check[fs.name] != fs.key {
+			t.Fatalf("%s: key %q, expected %q", fs.name, fs.key, check[fs.name])
+		}
+	}
+	if fs := ts.keys["-id"]; !fs.attr || !fs.omitempty {
+		t.Fatalf("-id: %#v", fs)
+	}
+	if !ts.keys["Skip"].skip {
+		t.Fatal("Skip not skipped")
+	}
+}
+
+var benchData = []byte(`<doc>
+	<name>widget</name>
+	<price>1.25</price>
+	<sub><a>1</a><b>2</b><c>3</c></sub>
+	<list><item>1</item><item>2</item><item>3</item></list>
+	<extra>x</extra>
+</doc>`)
+
+type benchSub struct {
+	A string `xml:"a"`
+	B string `xml:"b"`
+	C string `xml:"c"`
+	D string `xml:"d"`
+}
+
+type benchList struct {
+	Item []string `xml:"item"`
+}
+
+type benchDoc struct {
+	Name  string    `xml:"name"`
+	Price float64   `xml:"price"`
+	Qty   int       `xml:"qty"`
+	Sub   benchSub  `xml:"sub"`
+	List  benchList `xml:"list"`
+}
+
+func BenchmarkMissingXMLTags(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _, _ = MissingXMLTags(benchData, benchDoc{})
+	}
+}
+
+func BenchmarkUnknownXMLTags(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _, _ = UnknownXMLTags(benchData, benchDoc{})
+	}
+}
+
+var benchFlatData = []byte(`<msg id="42">
+	<name>widget</name>
+	<price>1.25</price>
+	<qty>3</qty>
+	<sku>W-1</sku>
+	<tag>a</tag><tag>b</tag>
+	<note/>
+	<extra>x</extra>
+</msg>`)
+
+type benchFlat struct {
+	ID    string   `xml:"id,attr"`
+	Name  string   `xml:"name"`
+	Price float64  `xml:"price"`
+	Qty   int      `xml:"qty"`
+	SKU   string   `xml:"sku"`
+	Tags  []string `xml:"tag"`
+	Note  string   `xml:"note,omitempty"`
+	Color *string  `xml:"color"`
+}
+
+func BenchmarkMissingXMLTagsFlat(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _, _ = MissingXMLTags(benchFlatData, benchFlat{})
+	}
+}
+
+func BenchmarkUnknownXMLTagsFlat(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _, _ = UnknownXMLTags(benchFlatData, benchFlat{})
+	}
+}
+
+// the traversal alone, without decoding the XML data
+func BenchmarkCheckMembersFlat(b *testing.B) {
+	m, _ := mxj.NewMapXml(benchFlatData)
+	val := reflect.ValueOf(benchFlat{})
+	o := currentOptions()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		checkMembers(m["msg"], val, &tagList{opts: o}, "")
+	}
+}
+
+func BenchmarkCheckAllTagsFlat(b *testing.B) {
+	m, _ := mxj.NewMapXml(benchFlatData)
+	val := reflect.ValueOf(benchFlat{})
+	o := currentOptions()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		checkAllTags(m["msg"], val, &tagList{opts: o}, "")
+	}
+}
+
+func BenchmarkNewTypeSpec(b *testing.B) {
+	typ := reflect.TypeOf(benchDoc{})
+	for i := 0; i < b.N; i++ {
+		_ = newTypeSpec(typ, "-")
+	}
+}
+
+func BenchmarkGetTypeSpec(b *testing.B) {
+	typ := reflect.TypeOf(benchDoc{})
+	for i := 0; i < b.N; i++ {
+		_ = getTypeSpec(typ, "-")
+	}
+}
+
+func TestTextUnmarshalerLeaf(t *testing.T) {
+	type test struct {
+		Name string    `xml:"name"`
+		When time.Time `xml:"when"`
+	}
+	data := []byte(`<doc><name>event</name><when>2019-05-25T10:00:00Z</when></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+
+	mems, _, err = MissingXMLTags([]byte(`<doc><name>event</name></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "when" {
+		t.Fatal("missing:", mems)
+	}
+}
+
+func TestFlatTypeSpec(t *testing.T) {
+	if !getTypeSpec(reflect.TypeOf(benchFlat{}), "-").flat {
+		t.Fatal("benchFlat isn't flat")
+	}
+	if getTypeSpec(reflect.TypeOf(benchDoc{}), "-").flat {
+		t.Fatal("benchDoc is flat")
+	}
+
+	// the results are the same without the fast path
+	docs := [][]byte{
+		benchFlatData,
+		[]byte(`<msg><name><first>a</first></name><tag><x/></tag><price>1</price></msg>`),
+		[]byte(`<msg id="1" other="2"/>`),
+		[]byte(`<msg>text</msg>`),
+	}
+	check := func() (res []string) {
+		for _, d := range docs {
+			m, _, err := MissingXMLTags(d, benchFlat{})
+			u, _, _ := UnknownXMLTags(d, &benchFlat{})
+			sort.Strings(u)
+			res = append(res, fmt.Sprint(m, u, err))
+		}
+		return res
+	}
+	fast := check()
+	ts := getTypeSpec(reflect.TypeOf(benchFlat{}), "-")
+	ts.flat = false
+	defer func() { ts.flat = true }()
+	if slow := check(); !reflect.DeepEqual(fast, slow) {
+		t.Fatal("fast path:", fast, slow)
+	}
+}
+
+func TestUnnamedMemberTags(t *testing.T) {
+	type sub struct {
+		Val string `xml:",omitempty"`
+	}
+	type test struct {
+		Note  string `xml:",omitempty"`
+		Ident string `xml:",attr"`
+		Lang  string `xml:",attr,omitempty"`
+		Sub   sub    `xml:","`
+		Plain string
+	}
+
+	ts := getTypeSpec(reflect.TypeOf(test{}), "-")
+	keys := make([]string, len(ts.fields))
+	for i, f := range ts.fields {
+		keys[i] = f.key
+	}
+	if !reflect.DeepEqual(keys, []string{"Note", "-Ident", "-Lang", "Sub", "Plain"}) {
+		t.Fatal("keys:", keys)
+	}
+
+	// the member name is used for matching and reporting
+	data := []byte(`<doc Ident="1"><Sub><X/></Sub><Plain/></doc>`)
+	mems, _, _ := MissingXMLTags(data, test{})
+	tags, _, _ := UnknownXMLTags(data, test{})
+	if len(mems) != 0 || len(tags) != 1 || tags[0] != "Sub.X" {
+		t.Fatal("omitempty:", mems, tags)
+	}
+
+	IgnoreOmitemptyTag(false)
+	SetMembersToIgnore("Sub.Val")
+	defer func() {
+		IgnoreOmitemptyTag(true)
+		SetMembersToIgnore()
+	}()
+	mems, _, _ = MissingXMLTags(data, test{})
+	if !reflect.DeepEqual(mems, []string{"Note", "-Lang"}) {
+		t.Fatal("no omitempty:", mems)
+	}
+	mems, _, _ = MissingXMLTagsStd(data, test{})
+	if !reflect.DeepEqual(mems, []string{"Note", "-Lang"}) {
+		t.Fatal("std:", mems)
+	}
+	if tags = MisplacedXMLTags([]byte(`<doc><Ident>1</Ident></doc>`), test{}); len(tags) != 1 || tags[0] != "Ident" {
+		t.Fatal("misplaced:", tags)
+	}
+}
+
+func TestCdataMemberTag(t *testing.T) {
+	type script struct {
+		Lang string `xml:"lang,attr"`
+		Code string `xml:",cdata"`
+	}
+	type test struct {
+		Script script `xml:"script"`
+		Note   string `xml:",cdata"`
+	}
+
+	data := []byte(`<doc><script lang="js"><![CDATA[if (a < b) {}]]></script>note</doc>`)
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+
+	// a simple element
+	data = []byte(`<doc><script><![CDATA[x]]></script></doc>`)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if !reflect.DeepEqual(mems, []string{"script.-lang", "#text"}) {
+		t.Fatal("simple:", mems)
+	}
+	if tags, _, _ = UnknownXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("simple unknown:", tags)
+	}
+}
+
+func TestCoNamedAttrAndElement(t *testing.T) {
+	type sub struct {
+		Elem string `xml:"v"`
+		Attr string `xml:"v,attr"`
+	}
+	type test struct {
+		Elem string `xml:"v"`
+		Attr string `xml:"v,attr"`
+		Sub  sub    `xml:"sub"`
+	}
+
+	// both supplied
+	data := []byte(`<doc v="1"><v>2</v><sub v="3"><v>4</v></sub></doc>`)
+	mems, _, _ := MissingXMLTags(data, test{})
+	tags, _, _ := UnknownXMLTags(data, test{})
+	if len(mems) != 0 || len(tags) != 0 {
+		t.Fatal("both:", mems, tags)
+	}
+	if tags = MisplacedXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("misplaced:", tags)
+	}
+
+	// one of each supplied
+	data = []byte(`<doc v="1"><sub><v>4</v></sub></doc>`)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if !reflect.DeepEqual(mems, []string{"v", "sub.-v"}) {
+		t.Fatal("missing:", mems)
+	}
+	if tags, _, _ = UnknownXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+	if tags = MisplacedXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("one misplaced:", tags)
+	}
+	tags, _, _ = PresentXMLTags(data, test{})
+	if !reflect.DeepEqual(tags, []string{"-v", "sub", "sub.v"}) {
+		t.Fatal("present:", tags)
+	}
+}
+
+type namedItem struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name"`
+}
+
+type (
+	namedItems []namedItem
+	namedPtrs  []*namedItem
+	namedTags  []string
+	namedMeta  map[string]string
+)
+
+// namedCSV is a named slice type that decodes its own character data, a list
+// of item names.
+type namedCSV []namedItem
+
+func (c *namedCSV) UnmarshalText(b []byte) error {
+	for _, name := range strings.Split(string(b), ",") {
+		*c = append(*c, namedItem{Name: name})
+	}
+	return nil
+}
+
+func TestNamedSliceAndMapMembers(t *testing.T) {
+	type test struct {
+		Items namedItems `xml:"item"`
+		Ptrs  namedPtrs  `xml:"ptr"`
+		Tags  namedTags  `xml:"tag"`
+		CSV   namedCSV   `xml:"csv"`
+		Meta  namedMeta  `xml:"meta,omitempty"`
+	}
+	// the same members with unnamed types
+	type plain struct {
+		Items []namedItem       `xml:"item"`
+		Ptrs  []*namedItem      `xml:"ptr"`
+		Tags  []string          `xml:"tag"`
+		CSV   []string          `xml:"csv"`
+		Meta  map[string]string `xml:"meta,omitempty"`
+	}
+	data := []byte(`<doc>
+		<item id="1"><name>a</name><x/></item>
+		<item><name>b</name></item>
+		<ptr id="2"><y/></ptr>
+		<tag>t</tag><tag>u</tag>
+		<csv>a,b</csv>
+	</doc>`)
+
+	for _, val := range []interface{}{test{}, plain{}} {
+		mems, _, err := MissingXMLTags(data, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(mems, []string{"item.-id", "ptr.name"}) {
+			t.Fatalf("%T missing: %v", val, mems)
+		}
+		tags, _, err := UnknownXMLTags(data, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(tags)
+		if !reflect.DeepEqual(tags, []string{"item.x", "ptr.y"}) {
+			t.Fatalf("%T unknown: %v", val, tags)
+		}
+	}
+
+	// the named slice type that's a leaf has no member paths
+	added, removed := DiffStructTags(test{}, struct{}{})
+	want := []string{"csv", "item", "item.-id", "item.name", "meta", "ptr", "ptr.-id", "ptr.name", "tag"}
+	if len(added) != 0 || !reflect.DeepEqual(removed, want) {
+		t.Fatal("diff:", added, removed)
+	}
+	if !isFlatType(reflect.TypeOf(namedCSV{})) || isFlatType(reflect.TypeOf(namedPtrs{})) {
+		t.Fatal("isFlatType")
+	}
+}
+
+type embBase struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name"`
+}
+
+type EmbMeta struct {
+	Author string `xml:"author"`
+}
+
+type embAudit struct {
+	Created string `xml:"created"`
+	Title   string `xml:"title,omitempty"`
+}
+
+type embDoc struct {
+	embBase                // promoted: -id, name
+	EmbMeta   `xml:"meta"` // promoted, the tag is ignored: author
+	*embAudit              // promoted, via a nil pointer: created, title
+	Title     string       `xml:"title"` // hides embAudit.Title
+}
+
+func TestEmbeddedStructs(t *testing.T) {
+	// the promoted members are checked at the level of the struct, including
+	// those of the tagged embedded struct, as encoding/xml decodes them
+	data := []byte(`<doc id="1"><name>a</name><author>b</author><created>c</created><title>d</title></doc>`)
+	mems, _, err := MissingXMLTags(data, embDoc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, embDoc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+	// encoding/xml can't allocate the unexported *embAudit, so cross-check
+	// without it
+	type crossDoc struct {
+		embBase
+		EmbMeta `xml:"meta"`
+	}
+	diff, _, err := Cross(data, crossDoc{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 0 {
+		t.Fatal("cross:", diff)
+	}
+
+	// the members aren't known by the embedded type names, nor by the tag of
+	// the tagged embedded struct
+	data = []byte(`<doc><embBase><name>a</name></embBase><meta><author>b</author></meta><created>c</created></doc>`)
+	mems, _, _ = MissingXMLTags(data, &embDoc{})
+	sort.Strings(mems)
+	want := []string{"-id", "author", "name", "title"}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("missing promoted:", mems)
+	}
+	tags, _, _ = UnknownXMLTags(data, &embDoc{})
+	sort.Strings(tags)
+	want = []string{"embBase", "meta"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("unknown promoted:", tags)
+	}
+
+	tags, _ = ExpectedXMLTags(embDoc{})
+	want = []string{"-id", "author", "created", "name", "title"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("expected:", tags)
+	}
+}
@@ -0,0 +1,100 @@
+// fragment.go - check XML fragments that have multiple top-level elements
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+
+	"github.com/clbanning/mxj"
+)
+
+// FragmentRoot is the tag of the synthetic root element that XML fragments are
+// wrapped in by MissingXMLTagsFragment and UnknownXMLTagsFragment.
+const FragmentRoot = "fragment"
+
+// MissingXMLTagsFragment is MissingXMLTags for a XML fragment - XML data that
+// may have several top-level elements without a wrapping root element, e.g.,
+// "<a>...</a><b>...</b>".  Each top-level element is checked against 'val' as the
+// root of a document, so the ignore lists and SetMaxDepth apply relative to it, and
+// the missing tags are prepended with the tag of the top-level element, e.g.,
+// "a.e2", "b.e2".  The top-level elements are checked in the sorted order of their
+// tags.  The root tag returned is FragmentRoot.
+func MissingXMLTagsFragment(b []byte, val interface{}) ([]string, string, error) {
+	var s tagList
+
+	m, err := newMapXmlFragment(b)
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	rval := reflect.ValueOf(val)
+	checkFragment(m, &s, func(v interface{}) {
+		checkMembers(v, rval, &s, "")
+	})
+	return s.tags, FragmentRoot, s.err
+}
+
+// UnknownXMLTagsFragment is UnknownXMLTags for a XML fragment - XML data that
+// may have several top-level elements without a wrapping root element, e.g.,
+// "<a>...</a><b>...</b>".  Each top-level element is checked against 'val' as the
+// root of a document, as for MissingXMLTagsFragment, and the unknown tags are
+// prepended with the tag of the top-level element, e.g., "a.e3", "b.-attr".  A
+// simple top-level element, <a>value</a>, has no unknown tags.  The root tag
+// returned is FragmentRoot.
+func UnknownXMLTagsFragment(b []byte, val interface{}) ([]string, string, error) {
+	var s tagList
+
+	m, err := newMapXmlFragment(b)
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	s.nsDecls(b)
+	rval := reflect.ValueOf(val)
+	checkFragment(m, &s, func(v interface{}) {
+		if _, ok := v.(map[string]interface{}); ok {
+			checkAllTags(v, rval, &s, "")
+		}
+	})
+	return s.tags, FragmentRoot, s.err
+}
+
+// checkFragment calls 'check' for each of the top-level elements of the fragment
+// map 'm', in sorted order, and prepends the tag of the element to the tags that
+// are added to 's'.
+func checkFragment(m map[string]interface{}, s *tagList, check func(v interface{})) {
+	for _, k := range sortedKeys(m) {
+		list, ok := m[k].([]interface{})
+		if !ok {
+			list = []interface{}{m[k]}
+		}
+		for _, v := range list {
+			if s.done {
+				return
+			}
+			n := len(s.tags)
+			check(v)
+			for i := n; i < len(s.tags); i++ {
+				s.tags[i] = k + "." + s.tags[i]
+			}
+		}
+	}
+}
+
+// newMapXmlFragment wraps the fragment 'b' in a FragmentRoot element and returns
+// the map of the top-level elements.  Any BOM or XML declaration is dropped since
+// they can't occur inside the FragmentRoot element.
+func newMapXmlFragment(b []byte) (map[string]interface{}, error) {
+	b = skipProlog(b)
+	doc := make([]byte, 0, len(b)+2*len(FragmentRoot)+5)
+	doc = append(doc, "<"+FragmentRoot+">"...)
+	doc = append(doc, b...)
+	doc = append(doc, "</"+FragmentRoot+">"...)
+	m, err := mxj.NewMapXml(doc)
+	if err != nil {
+		return nil, err
+	}
+	mm, _ := m[FragmentRoot].(map[string]interface{})
+	return mm, nil
+}
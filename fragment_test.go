@@ -0,0 +1,92 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestXMLTagsFragment(t *testing.T) {
+	data := []byte(`<a><e1>test</e1></a>
+		<b attr="x"><e1>test</e1><e2>more</e2><e3>extra</e3></b>`)
+
+	type test struct {
+		E1 string `xml:"e1"`
+		E2 string `xml:"e2"`
+	}
+
+	mems, root, err := MissingXMLTagsFragment(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != FragmentRoot {
+		t.Fatal("root:", root)
+	}
+	if !sameTags(mems, []string{"a.e2"}) {
+		t.Fatal("missing:", mems)
+	}
+
+	tags, _, err := UnknownXMLTagsFragment(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameTags(tags, []string{"b.-attr", "b.e3"}) {
+		t.Fatal("unknown:", tags)
+	}
+
+	// repeated top-level elements
+	data = []byte(`<a><e1>test</e1></a><a><e2>test</e2></a>`)
+	mems, _, err = MissingXMLTagsFragment(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameTags(mems, []string{"a.e1", "a.e2"}) {
+		t.Fatal("missing:", mems)
+	}
+}
+
+func TestXMLTagsFragmentRoots(t *testing.T) {
+	type sub struct {
+		S string `xml:"s"`
+		P *sub   `xml:"p,omitempty"`
+	}
+	type test struct {
+		E1  string `xml:"e1"`
+		E2  string `xml:"e2"`
+		Sub sub    `xml:"sub"`
+	}
+
+	// the top-level elements are checked in sorted order, a simple element
+	// has no unknown tags
+	data := []byte(`<c><e1>x</e1><e9>y</e9></c><a><e3>z</e3></a><b>1</b>`)
+	tags, _, err := UnknownXMLTagsFragment(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a.e3", "c.e9"}) {
+		t.Fatal("unknown:", tags)
+	}
+
+	// the ignore lists are relative to each top-level element
+	SetMembersToIgnore("e2", "sub.s")
+	defer SetMembersToIgnore()
+	data = []byte(`<b><e1>x</e1><sub/></b><a><sub/></a>`)
+	mems, _, err := MissingXMLTagsFragment(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mems, []string{"a.e1"}) {
+		t.Fatal("missing:", mems)
+	}
+
+	// as is the depth
+	SetMaxDepth(2)
+	defer SetMaxDepth(0)
+	data = []byte(`<a><e1>x</e1><sub><s>y</s></sub></a>`)
+	if _, _, err = UnknownXMLTagsFragment(data, test{}); err != nil {
+		t.Fatal("depth 2:", err)
+	}
+	data = []byte(`<a><sub><p><s>y</s></p></sub></a>`)
+	if _, _, err = UnknownXMLTagsFragment(data, test{}); err == nil {
+		t.Fatal("depth 3: no error")
+	}
+}
@@ -0,0 +1,52 @@
+// generic.go - type-parameterized entry points
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.18
+
+package checkxml
+
+import (
+	"io"
+	"reflect"
+)
+
+// The functions in this file are the main entry points with the struct type as a
+// type parameter rather than a value - e.g., MissingXMLTagsOf[MyStruct](b) is
+// MissingXMLTags(b, MyStruct{}).  T may be a struct or a pointer to a struct.
+
+// typeOf returns the reflect.Type of T, even if T is a pointer type.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// MissingXMLTagsOf is MissingXMLTags for the struct type T.
+func MissingXMLTagsOf[T any](b []byte) ([]string, string, error) {
+	return MissingXMLTags(b, zeroValue(typeOf[T]()))
+}
+
+// UnknownXMLTagsOf is UnknownXMLTags for the struct type T.
+func UnknownXMLTagsOf[T any](b []byte) ([]string, string, error) {
+	return UnknownXMLTags(b, zeroValue(typeOf[T]()))
+}
+
+// MissingXMLTagsReaderOf is MissingXMLTagsReader for the struct type T.
+func MissingXMLTagsReaderOf[T any](r io.Reader) ([]string, string, error) {
+	return MissingXMLTagsReader(r, zeroValue(typeOf[T]()))
+}
+
+// UnknownXMLTagsReaderOf is UnknownXMLTagsReader for the struct type T.
+func UnknownXMLTagsReaderOf[T any](r io.Reader) ([]string, string, error) {
+	return UnknownXMLTagsReader(r, zeroValue(typeOf[T]()))
+}
+
+// PresentXMLTagsOf is PresentXMLTags for the struct type T.
+func PresentXMLTagsOf[T any](b []byte) ([]string, string, error) {
+	return PresentXMLTags(b, zeroValue(typeOf[T]()))
+}
+
+// NewValidatorOf is NewValidator for the struct type T.
+func NewValidatorOf[T any](opts ...Option) *Validator {
+	return NewValidator(typeOf[T](), opts...)
+}
@@ -0,0 +1,58 @@
+//go:build go1.18
+
+package checkxml
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestGenericEntryPoints(t *testing.T) {
+	type test struct {
+		ID  string `xml:"id,attr"`
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	data := []byte(`<doc id="1"><ok>true</ok><other/></doc>`)
+
+	mems, root, err := MissingXMLTagsOf[test](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(mems) != 1 || mems[0] != "why" {
+		t.Fatal("missing:", root, mems)
+	}
+	mems, _, _ = MissingXMLTagsOf[*test](data)
+	if len(mems) != 1 || mems[0] != "why" {
+		t.Fatal("pointer missing:", mems)
+	}
+	tags, _, err := UnknownXMLTagsOf[test](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "other" {
+		t.Fatal("unknown:", tags)
+	}
+	mems, _, _ = MissingXMLTagsReaderOf[test](bytes.NewReader(data))
+	if len(mems) != 1 || mems[0] != "why" {
+		t.Fatal("reader missing:", mems)
+	}
+	tags, _, _ = UnknownXMLTagsReaderOf[*test](bytes.NewReader(data))
+	if len(tags) != 1 || tags[0] != "other" {
+		t.Fatal("reader unknown:", tags)
+	}
+	tags, _, _ = PresentXMLTagsOf[test](data)
+	if !reflect.DeepEqual(tags, []string{"-id", "ok"}) {
+		t.Fatal("present:", tags)
+	}
+
+	v := NewValidatorOf[test](WithTagsToIgnore("other"))
+	if v.Type() != reflect.TypeOf(test{}) {
+		t.Fatal("type:", v.Type())
+	}
+	mems, tags, _, _ = v.Validate(data)
+	if len(mems) != 1 || len(tags) != 0 {
+		t.Fatal("validator:", mems, tags)
+	}
+}
@@ -0,0 +1,176 @@
+// keys.go - compare the XML data tags with a prototype map rather than a struct
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"sort"
+
+	"github.com/clbanning/mxj"
+)
+
+// MissingKeys is MissingXMLTags for a schema that is a prototype of the XML data
+// rather than a struct definition.  'proto' is the map[string]interface{} value of
+// a root element - e.g., the value of the root key of the mxj.Map of a prototype
+// document - and the dot-notation tags of its keys that aren't in the XML data
+// are returned along with the XML data root tag.  The root tags aren't compared.
+//
+//	Example:
+//		pm, _ := mxj.NewMapXml(protoDoc)
+//		tags, root, err := checkxml.MissingKeys(data, pm["doc"].(map[string]interface{}))
+//
+// The keys are compared as in the mxj.Map representation - attribute keys have the
+// attribute prefix, see SetAttrPrefix, and the character data, "#text", isn't
+// compared.  A list value in 'proto' is represented by its first element, and each
+// element of a list in the XML data is checked against it.  The keys at each level
+// are reported in sorted order; each tag is reported once.  The ignore lists and
+// flags that refer to struct members aren't used.
+func MissingKeys(b []byte, proto map[string]interface{}) ([]string, string, error) {
+	var s tagList
+	root, v, err := rootValue(b)
+	if err != nil {
+		return nil, "", err
+	}
+	missingKeys(v, proto, &s, "", make(map[string]bool))
+	return s.tags, root, s.err
+}
+
+// UnknownKeys is UnknownXMLTags for a schema that is a prototype of the XML data,
+// 'proto', as for MissingKeys.  The dot-notation tags of the XML data keys that
+// aren't in 'proto' are returned along with the XML data root tag.  Any tags listed
+// with SetTagsToIgnore are not reported.
+func UnknownKeys(b []byte, proto map[string]interface{}) ([]string, string, error) {
+	var s tagList
+	root, v, err := rootValue(b)
+	if err != nil {
+		return nil, "", err
+	}
+	unknownKeys(v, proto, &s, "", make(map[string]bool))
+	return s.tags, root, s.err
+}
+
+// rootValue decodes the XML data and returns the root tag and its value.
+func rootValue(b []byte) (string, interface{}, error) {
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return "", nil, newParseError(err, b)
+	}
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+	return root, v, nil
+}
+
+// protoValue returns the value of 'v' that represents a list.
+func protoValue(v interface{}) interface{} {
+	if list, ok := v.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		return list[0]
+	}
+	return v
+}
+
+// sortedKeys returns the keys of 'm' in sorted order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// missingKeys reports the keys of the prototype map 'proto' that aren't in the
+// mxj.Map value 'mv'; 'seen' prevents reporting a tag more than once.
+func missingKeys(mv interface{}, proto map[string]interface{}, s *tagList, key string, seen map[string]bool) {
+	if s.tooDeep(key) {
+		return
+	}
+	if list, ok := mv.([]interface{}); ok {
+		for _, lv := range list {
+			if s.done {
+				return
+			}
+			missingKeys(lv, proto, s, key, seen)
+		}
+		return
+	}
+	mm, _ := mv.(map[string]interface{})
+	var tkey string
+	for _, k := range sortedKeys(proto) {
+		if s.done {
+			return
+		}
+		if k == textKey {
+			continue
+		}
+		if key == "" {
+			tkey = k
+		} else {
+			tkey = key + "." + k
+		}
+		v, ok := mm[k]
+		if !ok && !seen[tkey] {
+			seen[tkey] = true
+			s.add(tkey, nil)
+		}
+		if pm, ok := protoValue(proto[k]).(map[string]interface{}); ok {
+			missingKeys(v, pm, s, tkey, seen)
+		}
+	}
+}
+
+// unknownKeys reports the keys of the mxj.Map value 'mv' that aren't in the
+// prototype map 'proto'; 'seen' prevents reporting a tag more than once.
+func unknownKeys(mv interface{}, proto map[string]interface{}, s *tagList, key string, seen map[string]bool) {
+	if s.tooDeep(key) {
+		return
+	}
+	if list, ok := mv.([]interface{}); ok {
+		for _, lv := range list {
+			if s.done {
+				return
+			}
+			unknownKeys(lv, proto, s, key, seen)
+		}
+		return
+	}
+	mm, ok := mv.(map[string]interface{})
+	if !ok {
+		return
+	}
+	o := s.opt()
+	var tkey string
+	for _, k := range sortedKeys(mm) {
+		if s.done {
+			return
+		}
+		if k == textKey {
+			continue
+		}
+		if key == "" {
+			tkey = k
+		} else {
+			tkey = key + "." + k
+		}
+		for _, sk := range o.skiptags {
+			if tkey == sk || globMatch(sk, tkey) {
+				goto next
+			}
+		}
+		if pv, ok := proto[k]; ok {
+			pm, _ := protoValue(pv).(map[string]interface{})
+			unknownKeys(mm[k], pm, s, tkey, seen)
+		} else if !seen[tkey] {
+			seen[tkey] = true
+			s.add(tkey, nil)
+		}
+	next:
+	}
+}
@@ -0,0 +1,65 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/clbanning/mxj"
+)
+
+func TestKeys(t *testing.T) {
+	pm, err := mxj.NewMapXml([]byte(`
+		<doc version="1">
+			<title>x</title>
+			<item id="1"><name>a</name><price>1</price></item>
+			<item id="2"><name>b</name></item>
+			<meta><author>me</author><tags><tag>t</tag></tags></meta>
+		</doc>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proto := pm["doc"].(map[string]interface{})
+
+	data := []byte(`
+		<doc version="2">
+			<title>y</title>
+			<item id="3"><name>c</name><price>3</price></item>
+			<item><name>d</name><price>4</price><qty>1</qty></item>
+			<meta><author>you</author><tags><tag>u</tag></tags></meta>
+		</doc>`)
+	tags, root, err := MissingKeys(data, proto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || !reflect.DeepEqual(tags, []string{"item.-id"}) {
+		t.Fatal("missing:", root, tags)
+	}
+	tags, _, err = UnknownKeys(data, proto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"item.qty"}) {
+		t.Fatal("unknown:", tags)
+	}
+
+	// a missing subtree is reported with all of its keys
+	data = []byte(`<doc version="2"><title>y</title><item id="3"><name>c</name><price>3</price></item><meta><note/></meta></doc>`)
+	tags, _, _ = MissingKeys(data, proto)
+	want := []string{"meta.author", "meta.tags", "meta.tags.tag"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("missing subtree:", tags)
+	}
+
+	if tags, _, _ = UnknownKeys(data, proto); !reflect.DeepEqual(tags, []string{"meta.note"}) {
+		t.Fatal("unknown subtree:", tags)
+	}
+	SetTagsToIgnore("meta.note")
+	defer SetTagsToIgnore()
+	if tags, _, _ = UnknownKeys(data, proto); len(tags) != 0 {
+		t.Fatal("ignore:", tags)
+	}
+
+	if _, _, err = MissingKeys([]byte(`<doc>`), proto); err == nil {
+		t.Fatal("no error")
+	}
+}
@@ -0,0 +1,83 @@
+// manifest.go - compare the XML data leaf paths with a list of expected paths
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// ValidateManifest compares the dot-notation leaf paths of the XML data - the
+// paths of the elements and attributes that have values, as returned by the
+// mxj.Map LeafPaths method - with a manifest of 'expected' paths, e.g., from an
+// external schema, without a struct definition.  The paths are relative to the XML
+// root element, as for MissingXMLTags, have no list subscripts, and attribute
+// paths have the attribute prefix, see SetAttrPrefix; e.g., "item.name" and
+// "item.-id".  The character data of an element with attributes is the path of the
+// element, but the root element has no path - XML data with a simple root
+// element, <doc>text</doc>, has no paths.  The 'expected' paths that aren't in the
+// XML data are returned as 'missing', in the order given, and the XML data paths
+// that aren't expected are returned as 'unknown', in sorted order; along with the
+// XML data root tag.
+//
+//	Example:
+//		expected := []string{"title", "item.-id", "item.name", "item.price"}
+//		missing, unknown, root, err := checkxml.ValidateManifest(data, expected)
+func ValidateManifest(b []byte, expected []string) (missing, unknown []string, root string, err error) {
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, nil, "", newParseError(err, b)
+	}
+	for root = range m {
+		break
+	}
+
+	actual := make(map[string]bool)
+	for _, p := range m.LeafPaths() {
+		// the root element's own value - e.g., <doc>text</doc> - has no path
+		if mp := manifestPath(p); mp != "" {
+			actual[mp] = true
+		}
+	}
+	want := make(map[string]bool, len(expected))
+	for _, p := range expected {
+		want[p] = true
+		if !actual[p] {
+			missing = append(missing, p)
+		}
+	}
+	for p := range actual {
+		if !want[p] {
+			unknown = append(unknown, p)
+		}
+	}
+	sort.Strings(unknown)
+	return missing, unknown, root, nil
+}
+
+// manifestPath converts the mxj.Map leaf path 'path' to a manifest path - without
+// the root tag, list subscripts, "[0]" or ".0", and any text key.
+func manifestPath(path string) string {
+	var keep []string
+	for i, seg := range strings.Split(path, ".") {
+		if i == 0 {
+			continue // the root tag
+		}
+		if j := strings.Index(seg, "["); j >= 0 {
+			seg = seg[:j]
+		}
+		if seg == "" || strings.Trim(seg, "0123456789") == "" {
+			continue // a list subscript - see mxj.LeafUseDotNotation
+		}
+		keep = append(keep, seg)
+	}
+	if n := len(keep); n > 0 && keep[n-1] == textKey {
+		keep = keep[:n-1]
+	}
+	return strings.Join(keep, ".")
+}
@@ -0,0 +1,66 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/clbanning/mxj"
+)
+
+func TestValidateManifest(t *testing.T) {
+	expected := []string{"title", "item.-id", "item.name", "item.price", "note"}
+	data := []byte(`
+		<doc>
+			<title>x</title>
+			<item id="1"><name>a</name><price>1</price></item>
+			<item id="2"><name>b</name><price>2</price></item>
+			<note lang="en">text</note>
+		</doc>`)
+	missing, unknown, root, err := ValidateManifest(data, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(missing) != 0 || !reflect.DeepEqual(unknown, []string{"note.-lang"}) {
+		t.Fatal(root, missing, unknown)
+	}
+
+	data = []byte(`
+		<doc>
+			<item><name>a</name><qty>1</qty></item>
+			<item id="2"><name>b</name><extra><x>1</x></extra></item>
+			<note/>
+		</doc>`)
+	missing, unknown, _, err = ValidateManifest(data, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(missing, []string{"title", "item.price"}) {
+		t.Fatal("missing:", missing)
+	}
+	if !reflect.DeepEqual(unknown, []string{"item.extra.x", "item.qty"}) {
+		t.Fatal("unknown:", unknown)
+	}
+
+	// list subscripts in dot-notation are dropped, too
+	mxj.LeafUseDotNotation(true)
+	defer mxj.LeafUseDotNotation(false)
+	missing, _, _, _ = ValidateManifest(data, expected)
+	if !reflect.DeepEqual(missing, []string{"title", "item.price"}) {
+		t.Fatal("dot-notation:", missing)
+	}
+
+	// the root element's own value has no path
+	for simple, n := range map[string]int{`<doc>text</doc>`: 1, `<doc/>`: 1, `<doc id="1">text</doc>`: 0} {
+		missing, unknown, root, err = ValidateManifest([]byte(simple), []string{"-id"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != "doc" || len(unknown) != 0 || len(missing) != n {
+			t.Fatal("simple:", simple, missing, unknown)
+		}
+	}
+
+	if _, _, _, err = ValidateManifest([]byte(`<doc>`), expected); err == nil {
+		t.Fatal("no error")
+	}
+}
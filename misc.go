@@ -6,6 +6,14 @@
 package checkxml
 
 import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -28,6 +36,8 @@ var skiptags []string
 //		 	"config".
 //		- Subelements are represented in a simplier hierarchical manner:
 //		 	"data.ignore"
+//		- A tag prefixed with "*." matches at any depth:
+//			"*.ignore" matches "ignore", "data.ignore", "x.data.ignore", etc.
 //
 func SetTagsToIgnore(s ...string) {
 	switch {
@@ -39,9 +49,107 @@ func SetTagsToIgnore(s ...string) {
 	}
 }
 
+// List of XML element subtrees to NOT validate.
+var skipsubtrees []string
+
+// SetSubtreesToIgnore maintains a list of XML elements, in the dot-notation of
+// SetTagsToIgnore, whose subtrees aren't validated at all: nothing in or below the
+// element is reported by UnknownXMLTags and no member of the corresponding struct
+// member, nor the member itself, is reported by MissingXMLTags.  E.g., "legacy"
+// ignores <doc><legacy>...</legacy></doc> however many stray children it has.
+// A value prefixed with "*." matches at any depth.  Calling SetSubtreesToIgnore
+// with no arguments clears the list.
+func SetSubtreesToIgnore(s ...string) {
+	switch {
+	case len(s) == 0 || s[0] == "":
+		skipsubtrees = []string{}
+	default:
+		skipsubtrees = make([]string, len(s))
+		copy(skipsubtrees, s)
+	}
+}
+
+// inSubtree reports whether the dot-notation 'tag' is an element listed by
+// SetSubtreesToIgnore, or is below one.
+func inSubtree(tag string, subtrees []string) bool {
+	for _, st := range subtrees {
+		if tag == st || strings.HasPrefix(tag, st+".") || globMatch(st, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// List of XML element tags that are known but deprecated.
+var deprecatedTags []string
+
+// SetDeprecatedTags maintains a list of XML tags, in the dot-notation of
+// SetTagsToIgnore, that are accepted but slated for removal.  If such a tag is in
+// the XML data it's reported as deprecated - in Result.Deprecated, see Check, or by
+// DeprecatedXMLTags - rather than as unknown, whether or not it corresponds to a
+// struct member; nothing in or below the element is checked.  A deprecated struct
+// member isn't reported as missing.  A tag prefixed with "*." matches at any depth.
+// Calling SetDeprecatedTags with no arguments clears the list.
+func SetDeprecatedTags(s ...string) {
+	switch {
+	case len(s) == 0 || s[0] == "":
+		deprecatedTags = []string{}
+	default:
+		deprecatedTags = make([]string, len(s))
+		copy(deprecatedTags, s)
+	}
+}
+
+// matchTag reports whether the dot-notation 'tag' is in 'list' - either exactly
+// or by a "*.<suffix>" value.
+func matchTag(tag string, list []string) bool {
+	for _, v := range list {
+		if tag == v || globMatch(v, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// attrSuffix marks a SetMembersToIgnore value as an attribute member, without
+// the attribute prefix; e.g., "doc.id@attr" is "doc.-id".
+const attrSuffix = "@attr"
+
 type skipmems struct {
 	val   string
-	depth int
+	depth int  // 0 for "*.<suffix>" values
+	attr  bool // 'val' had the attrSuffix, which has been removed
+}
+
+func newSkipmems(v string) skipmems {
+	var attr bool
+	if strings.HasSuffix(v, attrSuffix) {
+		v, attr = strings.TrimSuffix(v, attrSuffix), true
+	}
+	if strings.HasPrefix(v, "*.") {
+		return skipmems{v, 0, attr}
+	}
+	return skipmems{v, len(strings.Split(v, ".")), attr}
+}
+
+// path returns the dot-notation value of 'sm', with the attribute 'prefix' on
+// the last member name if the value had the attrSuffix.
+func (sm skipmems) path(prefix string) string {
+	if !sm.attr {
+		return sm.val
+	}
+	i := strings.LastIndex(sm.val, ".") + 1
+	return sm.val[:i] + prefix + sm.val[i:]
+}
+
+// globMatch reports whether the dot-notation 'tag' matches the ignore list value
+// 'pattern' if it is of the form "*.<suffix>" - i.e., 'tag' is <suffix> or ends
+// with ".<suffix>", so the suffix matches at any depth.
+func globMatch(pattern, tag string) bool {
+	if !strings.HasPrefix(pattern, "*.") {
+		return false
+	}
+	return tag == pattern[2:] || strings.HasSuffix(tag, pattern[1:])
 }
 
 var skipmembers []skipmems
@@ -50,6 +158,13 @@ var skipmembers []skipmems
 // for as tags in the XML-encoded data.  For hierarchical struct members provide the full path for
 // the member name using dot-notation. Calling SetMembersToIgnore with no arguments -
 // SetMembersToIgnore() - will clear the list.
+//
+// A member name is matched only at the depth of its dot-notation path; "a.b" does
+// not match "x.a.b".  Prefix the name with "*." to match it at any depth - e.g.,
+// "*.password" matches "password", "user.password", "db.user.password", etc.
+// Members with a `xml:",attr"` tag are named with the attribute prefix, as they
+// are reported by MissingXMLTags - e.g., "elem.-id" - or with the "@attr" suffix,
+// which doesn't depend on the prefix - e.g., "elem.id@attr" or "*.id@attr".
 func SetMembersToIgnore(s ...string) {
 	if len(s) == 0 {
 		skipmembers = skipmembers[:0]
@@ -57,7 +172,7 @@ func SetMembersToIgnore(s ...string) {
 	}
 	skipmembers = make([]skipmems, len(s))
 	for i, v := range s {
-		skipmembers[i] = skipmems{v, len(strings.Split(v, "."))}
+		skipmembers[i] = newSkipmems(v)
 	}
 }
 
@@ -69,7 +184,9 @@ var omitemptyOK = true
 // in the slice of missing XML tags any struct members that are tagged with "omitempty".
 // If the function is toggled or passed the optional argument 'false' then missing
 // XML tags may include those XML data tags that correspond to struct members with
-// an "omitempty" XML tag.
+// an "omitempty" XML tag.  An absent "omitempty" list or pointer member - e.g.,
+// `xml:"item,omitempty"` []Item - has no elements, so the members of its elements
+// aren't reported either.
 //
 // Calling IgnoreOmitemptyTag with no arguments toggles the handling on/off.  If
 // the alternative bool argument is passed, then the argument value determines the
@@ -82,6 +199,190 @@ func IgnoreOmitemptyTag(ok ...bool) {
 	omitemptyOK = ok[0]
 }
 
+// Struct members with "omitempty" tags that are, nonetheless, required.
+var omitemptyRequired map[string]bool
+
+// SetOmitemptyRequired maintains a list of XML tags in dot-notation for struct
+// members with an "omitempty" XML tag that should be reported as missing, as if
+// IgnoreOmitemptyTag(false) had been called for just those members.  This allows
+// a few "omitempty" members to be required while the rest remain optional.
+// Calling SetOmitemptyRequired with no arguments will clear the list.
+//
+//	Example:
+//		type doc struct {
+//			ID   string `xml:"id,omitempty"`
+//			Note string `xml:"note,omitempty"`
+//		}
+//		SetOmitemptyRequired("id")
+//		tags, _, _ := MissingXMLTags([]byte(`<doc/>`), doc{})
+//		fmt.Println(tags) // prints: [id]
+func SetOmitemptyRequired(s ...string) {
+	omitemptyRequired = make(map[string]bool, len(s))
+	for _, v := range s {
+		omitemptyRequired[v] = true
+	}
+}
+
+// SetRequiredMembers is SetOmitemptyRequired: the "omitempty" tag is often needed
+// for marshaling struct members that are required when the XML data is decoded, and
+// the members listed in dot-notation are reported as missing regardless of their
+// "omitempty" tag.  The two functions maintain the same list.
+func SetRequiredMembers(s ...string) {
+	SetOmitemptyRequired(s...)
+}
+
+// Are the members of absent optional struct members not missing.
+var suppressOptional bool
+
+// SuppressChildrenOfMissingOptional manages a flag that causes the members of an
+// optional struct member - one with an "omitempty" XML tag, see IgnoreOmitemptyTag -
+// whose element is absent from the XML data to not be reported as missing; the
+// optional sub-document is absent as a whole.  By default the members are reported,
+// e.g., "sub.id" for an absent <sub> element.  Calling SuppressChildrenOfMissingOptional
+// with no arguments will toggle the flag.
+func SuppressChildrenOfMissingOptional(ok ...bool) {
+	if len(ok) == 0 {
+		suppressOptional = !suppressOptional
+		return
+	}
+	suppressOptional = ok[0]
+}
+
+// Are tags matching unexported struct members unknown. By default they are.
+var unexportedKnown bool
+
+// ReportUnexportedAsKnown determines whether XML tags that match the name of an
+// unexported struct member are reported by UnknownXMLTags.  The encoding/xml
+// Unmarshal function cannot set unexported struct members, so by default they're
+// reported as unknown like any other tag.  If ReportUnexportedAsKnown(true) is called
+// the tags are treated as known-but-not-decoded and are not reported; they are
+// always available using UnexportedXMLTags.
+//
+// Calling ReportUnexportedAsKnown with no arguments toggles the handling on/off.  If
+// the alternative bool argument is passed, then the argument value determines
+// the handling behavior.
+func ReportUnexportedAsKnown(ok ...bool) {
+	if len(ok) == 0 {
+		unexportedKnown = !unexportedKnown
+		return
+	}
+	unexportedKnown = ok[0]
+}
+
+// Should elements with no value be treated as missing. By default they're not.
+var emptyAsMissing bool
+
+// TreatEmptyAsMissing determines whether struct members whose XML element or
+// attribute is present but has no value - e.g., <elem/> or <elem>  </elem> - are
+// reported as missing by MissingXMLTags.  By default an element that is present
+// is not missing even if it is empty.  Values consisting only of white space,
+// such as left by XML pretty-printers, are treated as empty.  The content of
+// CDATA sections, <![CDATA[...]]>, is decoded as character data, so an element
+// with a non-empty CDATA section is not empty.
+//
+// Calling TreatEmptyAsMissing with no arguments toggles the handling on/off.  If
+// the alternative bool argument is passed, then the argument value determines
+// the handling behavior.
+func TreatEmptyAsMissing(ok ...bool) {
+	if len(ok) == 0 {
+		emptyAsMissing = !emptyAsMissing
+		return
+	}
+	emptyAsMissing = ok[0]
+}
+
+// the emptiness predicate, if not isEmptyValue
+var emptyFunc func(interface{}) bool
+
+// SetEmptyFunc sets the predicate that determines whether an XML element or attribute
+// value is empty for TreatEmptyAsMissing and EmptyRequiredXMLTags.  The value is
+// the mxj.Map value - usually a string or, for an element with attributes or
+// subelements, a map[string]interface{}.  The default predicate treats an empty
+// string, after trimming white space, or an empty map as empty; SetEmptyFunc(nil)
+// restores it.
+//
+//	Example:
+//		checkxml.SetEmptyFunc(func(v interface{}) bool {
+//			s, ok := v.(string)
+//			return ok && (s == "" || s == "N/A" || s == "null")
+//		})
+func SetEmptyFunc(fn func(value interface{}) bool) {
+	emptyFunc = fn
+}
+
+// the tag name normalizer, if any
+var nameNormalizer func(string) string
+
+// SetNameNormalizer sets a function that is applied to both the XML data tags and
+// the struct member tags - without any attribute prefix - before they're compared
+// when there is no exact match; e.g., to match <created-at> and <created_at> to
+// a member with the tag `xml:"createdAt"`.  The reported tags keep the original
+// names - the struct member tag for MissingXMLTags and the XML data tag for
+// UnknownXMLTags.  The default, SetNameNormalizer(nil), requires an exact match,
+// as the encoding/xml decoder does.
+//
+//	Example:
+//		checkxml.SetNameNormalizer(func(s string) string {
+//			return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s))
+//		})
+func SetNameNormalizer(fn func(string) string) {
+	nameNormalizer = fn
+}
+
+// compare tags without regard to case
+var caseInsensitive bool
+
+// SetCaseInsensitive determines whether the XML data tags and the struct member
+// tags are compared without regard to case when there is no exact match; e.g.,
+// <Name> matches a member with the tag `xml:"name"`.  As with SetNameNormalizer,
+// the reported tags keep the original names.  If several XML data tags match a
+// member - e.g., <Item> and <ITEM> - the first in sort order is checked against
+// the member.  By default an exact match is required, as the encoding/xml decoder
+// does.
+//
+// Calling SetCaseInsensitive with no arguments toggles the handling on/off.  If
+// the alternative bool argument is passed, then the argument value determines the
+// handling behavior.
+func SetCaseInsensitive(ok ...bool) {
+	if len(ok) == 0 {
+		caseInsensitive = !caseInsensitive
+		return
+	}
+	caseInsensitive = ok[0]
+}
+
+// function applied to the XML data tags before they're compared
+var keyMapper func(string) string
+
+// SetKeyMapper sets a function that is applied to each XML data tag - the mxj.Map
+// key, with any attribute prefix - that doesn't exactly match a struct member tag
+// before it is compared again; e.g., to match <price_v2> to a member with the tag
+// `xml:"price"`.  Unlike SetNameNormalizer the function isn't applied to the struct
+// member tags.  It is applied before any SetNameNormalizer and SetCaseInsensitive
+// comparison.  The reported tags keep the original names - the struct member tag
+// for MissingXMLTags and the XML data tag for UnknownXMLTags.  The default,
+// SetKeyMapper(nil), leaves the XML data tags as they are.
+//
+//	Example:
+//		checkxml.SetKeyMapper(func(k string) string {
+//			return strings.TrimSuffix(k, "_v2")
+//		})
+func SetKeyMapper(fn func(string) string) {
+	keyMapper = fn
+}
+
+// isEmptyValue reports whether a mxj.Map value is an empty string - after trimming
+// white space - or an empty map.
+func isEmptyValue(v interface{}) bool {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v) == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
 // should we try to coerce the values to float64 or bool
 var mxjCast bool
 
@@ -93,10 +394,20 @@ var mxjCast bool
 func SetMxjCast(b ...bool) {
 	if len(b) == 0 {
 		mxjCast = !mxjCast
+		return
 	}
 	mxjCast = b[0]
 }
 
+// castFlag returns the mxj cast flag for a call: cast[0], if it's given, or
+// the SetMxjCast setting.
+func castFlag(cast []bool) bool {
+	if len(cast) > 0 {
+		return cast[0]
+	}
+	return mxjCast
+}
+
 
 // HasTags is a convenience function that takes the result slice from MissingTags
 // or UnknownTags and returns "true, nil" if the dot-notation 'check' values are
@@ -120,3 +431,568 @@ func HasTags(result []string, check ...string) (bool, []string) {
 	}
 	return true, nil
 }
+
+// TagDepth returns the nesting depth of the dot-notation XML tag 'tag' as reported
+// by MissingXMLTags or UnknownXMLTags - the number of its elements; e.g., 1 for
+// "elem" and 3 for "elem.sub.-id".  The root element isn't counted; "" is 0.
+func TagDepth(tag string) int {
+	if tag == "" {
+		return 0
+	}
+	return strings.Count(tag, ".") + 1
+}
+
+// maximum depth of the XML data that will be scanned; 0 is unlimited
+var maxDepth int
+
+// SetMaxDepth limits the depth of the XML data hierarchy that will be scanned.
+// If an element deeper than 'n' levels below the XML root is encountered the
+// scan is terminated and the MissingXMLTags and UnknownXMLTags functions return
+// an error - "max depth exceeded at path <tag>" - along with the tags found up to
+// that point.  This protects services that accept untrusted XML data from
+// maliciously deep documents.  SetMaxDepth(0) - the default - means the depth
+// is unlimited.
+func SetMaxDepth(n int) {
+	if n < 0 {
+		n = 0
+	}
+	maxDepth = n
+}
+
+// maximum number of bytes read by the io.Reader functions; 0 is unlimited
+var maxReadSize int64
+
+// ErrTooLarge is returned by the io.Reader functions if the XML document is longer
+// than the limit set by SetMaxReadSize.
+var ErrTooLarge = errors.New("document too large")
+
+// SetMaxReadSize limits the number of bytes of XML data that the io.Reader
+// functions - MissingXMLTagsReader, UnknownXMLTagsReaderMap, etc. - will read.
+// If the XML document isn't complete within 'n' bytes, ErrTooLarge is returned;
+// the limit is for the document, so any data that follows it isn't counted.
+// This protects services that accept untrusted XML data from memory exhaustion.
+// SetMaxReadSize(0) - the default - means the size is unlimited.
+func SetMaxReadSize(n int64) {
+	if n < 0 {
+		n = 0
+	}
+	maxReadSize = n
+}
+
+// maximum number of tags that will be reported; 0 is unlimited
+var maxResults int
+
+// ErrTruncated is returned along with the tags found when the number of tags
+// would exceed the limit set by SetMaxResults.
+var ErrTruncated = errors.New("max results exceeded")
+
+// SetMaxResults limits the number of tags that the MissingXMLTags and
+// UnknownXMLTags functions will report.  When the limit is reached the scan of
+// the XML data is terminated and, if there are more tags to report, the error
+// ErrTruncated is returned along with the first 'n' tags.  This bounds the
+// memory and time spent on pathological XML data when only the first few tags
+// are needed to reject it.  SetMaxResults(0) - the default - means there is no
+// limit.
+func SetMaxResults(n int) {
+	if n < 0 {
+		n = 0
+	}
+	maxResults = n
+}
+
+// Should a simple root element be an error. By default it isn't.
+var strictRootKind bool
+
+// ErrRootKind is returned by the MissingXMLTags functions, with no tags, for XML
+// data whose root element is a simple element - <doc>value</doc> - if
+// StrictRootKind(true) has been called.
+var ErrRootKind = errors.New("root element has no attributes or subelements")
+
+// StrictRootKind determines how the MissingXMLTags functions handle XML data whose
+// root element is a simple element, <doc>value</doc>, which has none of the struct
+// members.  By default the name of the struct type is reported as the missing tag.
+// If StrictRootKind(true) is called, then no tags are reported and ErrRootKind is
+// returned instead.
+//
+// Calling StrictRootKind with no arguments toggles the handling on/off.  If the
+// alternative bool argument is passed, then the argument value determines the
+// handling behavior.
+func StrictRootKind(ok ...bool) {
+	if len(ok) == 0 {
+		strictRootKind = !strictRootKind
+		return
+	}
+	strictRootKind = ok[0]
+}
+
+// the mxj.Map attribute key prefix
+var attrPrefix = "-"
+
+// SetAttrPrefix sets the prefix that is expected for attribute keys in the mxj.Map
+// representation of the XML data; the default is the mxj package default, "-".
+// If mxj.SetAttrPrefix has been called to change the mxj package prefix, then
+// SetAttrPrefix must be called with the same value so that struct members with
+// `xml:",attr"` tags match the attribute keys.  Attribute tags in the results
+// are also reported using the prefix - e.g., "elem.@attr" for SetAttrPrefix("@").
+//
+// NOTE: the prefix is global to the mxj package; SetAttrPrefix does not call
+// mxj.SetAttrPrefix.
+func SetAttrPrefix(s string) {
+	attrPrefix = s
+}
+
+// Should attributes be ignored. By default they're checked.
+var ignoreAttrs bool
+
+// IgnoreAllAttrs determines whether XML attributes are checked at all.  If
+// IgnoreAllAttrs(true) is called, then no XML attribute is reported by UnknownXMLTags
+// and no struct member with a `xml:",attr"` tag is reported by MissingXMLTags, so
+// only the XML elements are validated.  This is coarser, but more convenient,
+// than listing the attribute tags with SetTagsToIgnore and SetMembersToIgnore.
+//
+// Calling IgnoreAllAttrs with no arguments toggles the handling on/off.  If
+// the alternative bool argument is passed, then the argument value determines
+// the handling behavior.
+func IgnoreAllAttrs(ok ...bool) {
+	if len(ok) == 0 {
+		ignoreAttrs = !ignoreAttrs
+		return
+	}
+	ignoreAttrs = ok[0]
+}
+
+// Should a member match an attribute or an element of its name. By default only
+// the kind it's tagged as.
+var attrOrElement bool
+
+// MatchAttrOrElement determines whether a struct member matches XML data of the
+// other kind, attribute or element, if the data has none of its own kind.  If
+// MatchAttrOrElement(true) is called, then a member without an "attr" tag, e.g.,
+// `xml:"id"`, isn't reported by MissingXMLTags if the element has an "id"
+// attribute, and the attribute isn't reported by UnknownXMLTags; likewise a
+// `xml:"id,attr"` member matches an <id> subelement.  This accommodates XML data
+// producers that don't agree with the struct definition about what's an attribute;
+// NOTE: the encoding/xml decoder doesn't set the member from such data.
+//
+// Calling MatchAttrOrElement with no arguments toggles the handling on/off.  If
+// the alternative bool argument is passed, then the argument value determines
+// the handling behavior.
+func MatchAttrOrElement(ok ...bool) {
+	if len(ok) == 0 {
+		attrOrElement = !attrOrElement
+		return
+	}
+	attrOrElement = ok[0]
+}
+
+// Should the XML root tag be checked against the XMLName tag. By default it isn't.
+var checkRootOK bool
+
+// SetCheckRoot determines whether the MissingXMLTags functions check the XML data
+// root tag against the local name in the `xml` tag of the XMLName member of 'val',
+// if there is one.  If they don't match, the synthetic tag
+// "XMLName(expected=<name>,got=<root>)" is appended to the missing tags; the
+// encoding/xml Unmarshal function would fail to decode the XML data.
+//
+// If the XMLName tag has a namespace - `xml:"urn:example Invoice"` - the namespace
+// URI of the root element is also checked and a mismatch is reported as
+// "XMLName(expected=urn:example Invoice,got=urn:other Invoice)".  The root element
+// namespace isn't available to MissingXMLTagsReader and MissingXMLTagsReaderMap, so
+// only the local name is checked by them.
+//
+// Calling SetCheckRoot with no arguments toggles the handling on/off.  If the
+// alternative bool argument is passed, then the argument value determines the
+// handling behavior.
+func SetCheckRoot(ok ...bool) {
+	if len(ok) == 0 {
+		checkRootOK = !checkRootOK
+		return
+	}
+	checkRootOK = ok[0]
+}
+
+// Are the unknown tags reported in XML data order. By default they aren't.
+var docOrder bool
+
+// OrderByDocument determines whether the UnknownXMLTags functions report the
+// unknown tags in the order in which they first occur in the XML data - an
+// element's attributes follow the element and precede its child elements.  By
+// default they're reported in no particular order; the mxj.Map is a Go map.
+// Ordering the tags requires decoding the XML data a second time, with
+// mxj.NewMapXmlSeq, which preserves the element and attribute sequence, so it's
+// only done by the functions that have the XML data document as a []byte - and by
+// UnknownXMLTagsReaderMapRaw - not by the other UnknownXMLTagsReader functions,
+// WalkUnknownXMLTags, UnknownXMLTagsAt and UnknownXMLTagsFragment.
+// Tags with a namespace prefix in the XML data follow the other tags.
+// (See github.com/clbanning/mxj documentation of mxj.NewMapXmlSeq.)
+//
+// Calling OrderByDocument with no arguments toggles the handling on/off.  If the
+// alternative bool argument is passed, then the argument value determines the
+// handling behavior.
+func OrderByDocument(ok ...bool) {
+	if len(ok) == 0 {
+		docOrder = !docOrder
+		return
+	}
+	docOrder = ok[0]
+}
+
+// checkRoot reports the XML data root tag as "XMLName(expected=<name>,got=<root>)"
+// if SetCheckRoot(true) has been called and it doesn't match the XMLName member
+// tag of 'val'.  If the XMLName tag has a namespace and the XML data 'b' is
+// available, the namespace of the root element is also checked.
+func (t *tagList) checkRoot(root string, b []byte, val interface{}) {
+	o := t.opt()
+	if !o.checkRoot {
+		return
+	}
+	typ := reflect.TypeOf(val)
+	if typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return
+	}
+	ts := getTypeSpec(typ, o.attrPrefix)
+	if ts.xmlName == "" {
+		return
+	}
+	if ts.xmlName != root {
+		t.add(fmt.Sprintf("XMLName(expected=%s,got=%s)", ts.xmlName, root), typ)
+		return
+	}
+	// mxj drops the namespace prefixes, so get the namespace from the XML data
+	if ts.xmlNS == "" || b == nil {
+		return
+	}
+	if ns := rootNamespace(b); ns != ts.xmlNS {
+		t.add(fmt.Sprintf("XMLName(expected=%s %s,got=%s %s)", ts.xmlNS, ts.xmlName, ns, root), typ)
+	}
+}
+
+// rootNamespace returns the namespace URI of the root element of the XML data.
+func rootNamespace(b []byte) string {
+	return rootElement(b).Space
+}
+
+// rootElement returns the name of the first element in the XML data, if any.
+func rootElement(b []byte) xml.Name {
+	d := xml.NewDecoder(bytes.NewReader(skipBOM(b)))
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return xml.Name{}
+		}
+		if se, ok := t.(xml.StartElement); ok {
+			return se.Name
+		}
+	}
+}
+
+// ParseErrorPrefixLen is the maximum length of ParseError.Prefix.
+const ParseErrorPrefixLen = 64
+
+// ParseError is returned by the functions that take the XML data as a []byte if
+// the data can't be decoded.  Use errors.As to get it:
+//
+//	var pe *checkxml.ParseError
+//	if errors.As(err, &pe) {
+//		log.Printf("bad XML: root=%q len=%d start=%q: %v", pe.Root, pe.Len, pe.Prefix, pe.Err)
+//	}
+type ParseError struct {
+	Err    error  // the error from decoding the XML data
+	Root   string // the XML root tag, if the start of the root element was decoded
+	Len    int    // the length of the XML data
+	Prefix []byte // the start of the XML data - at most ParseErrorPrefixLen bytes
+}
+
+func (e *ParseError) Error() string {
+	if e.Root == "" {
+		return fmt.Sprintf("parsing XML data: %v", e.Err)
+	}
+	return fmt.Sprintf("parsing XML data with root %s: %v", e.Root, e.Err)
+}
+
+// Unwrap returns the error from decoding the XML data.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// newParseError wraps the error 'err' from decoding the XML data 'b'.
+func newParseError(err error, b []byte) error {
+	n := len(b)
+	if n > ParseErrorPrefixLen {
+		n = ParseErrorPrefixLen
+	}
+	prefix := make([]byte, n)
+	copy(prefix, b)
+	return &ParseError{Err: err, Root: rootElement(b).Local, Len: len(b), Prefix: prefix}
+}
+
+// rootChild is a child element of the root element and its values.
+type rootChild struct {
+	tag  string
+	list []interface{}
+}
+
+// rootChildren returns the child elements of the root element value 'mv', in
+// tag order, for a top-level list; attributes and character data are omitted.
+func rootChildren(mv interface{}, o *options) []rootChild {
+	mm, _ := mv.(map[string]interface{})
+	children := make([]rootChild, 0, len(mm))
+	for k, v := range mm {
+		if k == textKey || isAttrTag(k, o.attrPrefix) {
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		children = append(children, rootChild{k, list})
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].tag < children[j].tag })
+	return children
+}
+
+// isAttrTag reports whether the last segment of the dot-notation 'tag' is
+// an attribute - has the attribute 'prefix'.
+func isAttrTag(tag, prefix string) bool {
+	return prefix != "" && strings.HasPrefix(tag[strings.LastIndex(tag, ".")+1:], prefix)
+}
+
+// the mxj.Map key for the character data of elements with attributes or
+// subelements.  It isn't a setting: mxj hard-codes "#text" and, unlike the
+// attribute prefix, has no setting for it - there's no mxj.SetTextKey - so the
+// keys that mxj decodes can't be anything else.
+const textKey = "#text"
+
+// zeroValue returns a zero value of type 't'; if 't' is a pointer type
+// the value is a pointer to a zero value of the type it points to.
+func zeroValue(t reflect.Type) interface{} {
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface()
+	}
+	return reflect.New(t).Elem().Interface()
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// skipBOM drops a leading UTF-8 byte order mark from the XML data.
+func skipBOM(b []byte) []byte {
+	return bytes.TrimPrefix(b, utf8BOM)
+}
+
+// skipProlog drops a leading UTF-8 byte order mark and XML declaration,
+// <?xml ... ?>, from the XML data.
+func skipProlog(b []byte) []byte {
+	b = bytes.TrimLeft(skipBOM(b), " \t\r\n")
+	if bytes.HasPrefix(b, []byte("<?xml")) {
+		if n := bytes.Index(b, []byte("?>")); n > 0 {
+			b = b[n+2:]
+		}
+	}
+	return b
+}
+
+// tagList accumulates the tags reported by checkMembers and checkAllTags.
+// If fn is not nil the tags are passed to fn instead of being saved and
+// the traversal is terminated when fn returns false.
+// For checkMembers types holds the struct member type of each missing tag;
+// for checkAllTags it holds the type of the struct enclosing the unknown tag
+// and values holds the mxj.Map value of the unknown tag.
+type tagList struct {
+	tags   []string
+	types  []reflect.Type
+	values []interface{}
+	fn     func(string) bool
+	done   bool
+	err    error // reason the traversal was terminated, if any
+	n      int   // number of tags reported
+
+	// tags that match unexported struct members - see checkAllTags
+	unexported []string
+	// tags for required members with empty values - see checkMembers
+	empty []string
+	// number of required members checked - see checkMembers
+	checked int
+	// tags for members set by the XML data - see checkMembers
+	present []string
+
+	opts *options // if nil, the package settings - see opt
+
+	ctx    context.Context // if not nil, checked periodically - see canceled
+	visits int
+
+	registered bool // the ignore lists for the type of 'val' were added - see applyRegistered
+	// deprecated tags found in the XML data - see checkKeys
+	deprecated []string
+	// struct types being checked without XML data - see checkMembers
+	absent map[reflect.Type]bool
+	// attribute keys of the xmlns:prefix="..." declarations - see nsDecls
+	nsdecls map[string]bool
+	// if not nil, the tags reported; each tag is reported once - see MissingXMLTagsAt
+	once map[string]bool
+}
+
+// opt returns the options for the traversal; if none were given, it's a
+// snapshot of the package settings.
+func (t *tagList) opt() *options {
+	if t.opts == nil {
+		t.opts = currentOptions()
+	}
+	return t.opts
+}
+
+func (t *tagList) add(tag string, typ reflect.Type) {
+	t.addValue(tag, typ, nil)
+}
+
+func (t *tagList) addValue(tag string, typ reflect.Type, val interface{}) {
+	if t.done || t.once[tag] {
+		return
+	}
+	if max := t.opt().maxResults; max > 0 && t.n == max {
+		t.fail(ErrTruncated)
+		return
+	}
+	t.n++
+	if t.once != nil {
+		t.once[tag] = true
+	}
+	if t.fn != nil {
+		t.done = !t.fn(tag)
+		return
+	}
+	t.tags = append(t.tags, tag)
+	t.types = append(t.types, typ)
+	t.values = append(t.values, val)
+}
+
+// addDeprecated records the deprecated tag 'tag' once; see SetDeprecatedTags.
+func (t *tagList) addDeprecated(tag string) {
+	for _, v := range t.deprecated {
+		if v == tag {
+			return
+		}
+	}
+	t.deprecated = append(t.deprecated, tag)
+}
+
+// fail terminates the traversal with the error 'err'.
+func (t *tagList) fail(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+	t.done = true
+}
+
+// tooDeep terminates the traversal if the dot-notation 'tag' is deeper than maxDepth.
+func (t *tagList) tooDeep(tag string) bool {
+	if max := t.opt().maxDepth; max > 0 && TagDepth(tag) > max {
+		t.fail(fmt.Errorf("max depth exceeded at path %s", tag))
+	}
+	return t.done
+}
+
+// cancelCheck is the number of traversal steps between checks of tagList.ctx.
+const cancelCheck = 256
+
+// canceled terminates the traversal with ctx.Err() if the Context is done.
+func (t *tagList) canceled() bool {
+	if t.ctx != nil {
+		if t.visits++; t.visits%cancelCheck == 0 {
+			if err := t.ctx.Err(); err != nil {
+				t.fail(err)
+			}
+		}
+	}
+	return t.done
+}
+
+// nsDecls records the xmlns:prefix="..." namespace declarations in the XML data
+// 'b'.  mxj keys them as attributes - "-prefix" for xmlns:prefix - so they can't
+// be told apart from attributes in the map; an attribute with the name of a
+// declared prefix is taken to be the declaration.
+func (t *tagList) nsDecls(b []byte) {
+	if !bytes.Contains(b, []byte("xmlns:")) {
+		return
+	}
+	d := xml.NewDecoder(bytes.NewReader(skipBOM(b)))
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			t.addNSDecls(se.Attr)
+		}
+	}
+}
+
+// addNSDecls records the xmlns:prefix="..." declarations in 'attrs'.
+func (t *tagList) addNSDecls(attrs []xml.Attr) {
+	for _, a := range attrs {
+		if a.Name.Space != "xmlns" {
+			continue
+		}
+		if t.nsdecls == nil {
+			t.nsdecls = make(map[string]bool)
+		}
+		t.nsdecls[t.opt().attrPrefix+a.Name.Local] = true
+	}
+}
+
+// isNSDecl reports whether the map key 'k' is a namespace declaration, the
+// default xmlns="..." or a recorded xmlns:prefix="..." - see nsDecls.
+func (t *tagList) isNSDecl(k string) bool {
+	return k == t.opt().attrPrefix+"xmlns" || t.nsdecls[k]
+}
+
+// ctxReader is an io.Reader that fails with ctx.Err() once the Context is done
+// and, if 'max' > 0, ends after 'max' bytes; a document that isn't complete by
+// then fails to decode and readErr returns ErrTooLarge.
+type ctxReader struct {
+	ctx      context.Context
+	r        io.Reader
+	max, n   int64
+	tooLarge bool // the decoder read past 'max' bytes
+}
+
+// newCtxReader returns a ctxReader for 'r' that reads at most 'max' bytes; see
+// SetMaxReadSize.
+func newCtxReader(ctx context.Context, r io.Reader, max int64) *ctxReader {
+	return &ctxReader{ctx: ctx, r: r, max: max}
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	if c.max <= 0 {
+		return c.r.Read(p)
+	}
+	// the data after 'max' bytes isn't read, so a document that ends at the
+	// limit is decoded whatever follows it
+	if c.n >= c.max {
+		c.tooLarge = true
+		return 0, io.EOF
+	}
+	if left := c.max - c.n; int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// readErr returns the error for the failure, 'err', to decode the XML data read
+// with 'c'; the decoder may have wrapped or replaced the error from Read.
+func (c *ctxReader) readErr(err error) error {
+	if c.ctx.Err() != nil {
+		return c.ctx.Err()
+	}
+	if c.tooLarge {
+		return ErrTooLarge
+	}
+	return err
+}
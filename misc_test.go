@@ -1,8 +1,15 @@
 package checkxml
 
 import (
-	// "fmt"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
+
+	"github.com/clbanning/mxj"
 )
 
 func TestHasTags(t *testing.T) {
@@ -31,3 +38,500 @@ func TestHasTags(t *testing.T) {
 		t.Fatalf("result has len %d: %v", len(v), v)
 	}
 }
+
+func TestSetMaxDepth(t *testing.T) {
+	type node struct {
+		N []node `xml:"n"`
+	}
+	data := []byte(`<doc>` + strings.Repeat("<n>", 50) + "<x/>" + strings.Repeat("</n>", 50) + `</doc>`)
+
+	SetMaxDepth(10)
+	defer SetMaxDepth(0)
+
+	_, _, err := UnknownXMLTags(data, node{})
+	if err == nil {
+		t.Fatal("no error")
+	}
+	if !strings.HasPrefix(err.Error(), "max depth exceeded at path n.n.n.n.n.n.n.n.n.n.n") {
+		t.Fatal(err)
+	}
+
+	type c struct {
+		D string `xml:"d"`
+	}
+	type b struct {
+		C c `xml:"c"`
+	}
+	type a struct {
+		B b `xml:"b"`
+	}
+	SetMaxDepth(2)
+	_, _, err = MissingXMLTags([]byte(`<a><b><c><d>1</d></c></b></a>`), a{})
+	if err == nil || err.Error() != "max depth exceeded at path b.c.d" {
+		t.Fatal(err)
+	}
+
+	SetMaxDepth(0)
+	tags, _, err := UnknownXMLTags(data, node{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || !strings.HasSuffix(tags[0], ".x") {
+		t.Fatal(tags)
+	}
+}
+
+func TestSetMaxResults(t *testing.T) {
+	type test struct {
+		Ok bool `xml:"ok"`
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<doc><ok>true</ok>")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&buf, "<e%d>%d</e%d>", i, i, i)
+	}
+	buf.WriteString("</doc>")
+	data := buf.Bytes()
+
+	SetMaxResults(5)
+	defer SetMaxResults(0)
+
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != ErrTruncated {
+		t.Fatal("err:", err)
+	}
+	if len(tags) != 5 {
+		t.Fatal("tags:", tags)
+	}
+
+	var n int
+	_, err = WalkUnknownXMLTags(data, test{}, func(string) bool {
+		n++
+		return true
+	})
+	if err != ErrTruncated {
+		t.Fatal("err:", err)
+	}
+	if n != 5 {
+		t.Fatal("calls:", n)
+	}
+
+	// exactly at the limit is not truncated
+	SetMaxResults(1000)
+	tags, _, err = UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1000 {
+		t.Fatal("tags:", len(tags))
+	}
+}
+
+func TestSkipBOM(t *testing.T) {
+	type test struct {
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	for _, data := range []string{
+		"\xef\xbb\xbf<doc><ok>true</ok><not>x</not></doc>",
+		"\xef\xbb\xbf<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<doc><ok>true</ok><not>x</not></doc>",
+	} {
+		mems, root, err := MissingXMLTags([]byte(data), test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != "doc" || len(mems) != 1 || mems[0] != "why" {
+			t.Fatal("missing:", root, mems)
+		}
+		tags, root, err := UnknownXMLTags([]byte(data), test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != "doc" || len(tags) != 1 || tags[0] != "not" {
+			t.Fatal("unknown:", root, tags)
+		}
+		mems, _, err = MissingXMLTagsStd([]byte(data), test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mems) != 1 || mems[0] != "why" {
+			t.Fatal("missing std:", mems)
+		}
+		mems, _, err = MissingXMLTagsFragment([]byte(data), test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mems) != 1 || mems[0] != "doc.why" {
+			t.Fatal("missing fragment:", mems)
+		}
+	}
+}
+
+func TestSetMxjCast(t *testing.T) {
+	defer SetMxjCast(false)
+
+	SetMxjCast(false)
+	SetMxjCast()
+	if !mxjCast {
+		t.Fatal("SetMxjCast() didn't toggle to true")
+	}
+	SetMxjCast()
+	if mxjCast {
+		t.Fatal("SetMxjCast() didn't toggle to false")
+	}
+	SetMxjCast(true)
+	if !mxjCast {
+		t.Fatal("SetMxjCast(true) didn't set flag")
+	}
+}
+
+func TestMapCastArg(t *testing.T) {
+	type test struct {
+		Ok  bool `xml:"ok"`
+		Num int  `xml:"num"`
+	}
+	data := []byte(`<doc><ok>true</ok><num>1</num></doc>`)
+
+	// the argument overrides the SetMxjCast setting for the call
+	_, m, _, err := MissingXMLTagsMap(data, test{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := m.ValueForPath("doc.num"); v != float64(1) {
+		t.Fatalf("cast: %T %v", v, v)
+	}
+	_, _, m, _ = UnknownXMLTagsMap(data, test{})
+	if v, _ := m.ValueForPath("doc.num"); v != "1" {
+		t.Fatalf("no cast: %T %v", v, v)
+	}
+
+	SetMxjCast(true)
+	defer SetMxjCast(false)
+	_, m, _, _ = MissingXMLTagsReaderMap(bytes.NewReader(data), test{}, false)
+	if v, _ := m.ValueForPath("doc.ok"); v != "true" {
+		t.Fatalf("reader: %T %v", v, v)
+	}
+	_, _, m, _, _ = UnknownXMLTagsReaderMapRaw(bytes.NewReader(data), test{})
+	if v, _ := m.ValueForPath("doc.ok"); v != true {
+		t.Fatalf("setting: %T %v", v, v)
+	}
+}
+
+func TestSetAttrPrefix(t *testing.T) {
+	mxj.SetAttrPrefix("@")
+	SetAttrPrefix("@")
+	defer func() {
+		mxj.SetAttrPrefix("-")
+		SetAttrPrefix("-")
+	}()
+
+	type why struct {
+		ID    string `xml:"id,attr"`
+		Maybe bool   `xml:"maybe"`
+	}
+	type test struct {
+		Version string `xml:"version,attr"`
+		Ok      bool   `xml:"ok"`
+		Why     why    `xml:"why"`
+	}
+	data := []byte(`<doc version="1"><ok>true</ok><why attr="x"><maybe>true</maybe></why></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "why.@id" {
+		t.Fatal("missing:", mems)
+	}
+	attrs, _, err := MissingXMLAttrs(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 1 || attrs[0] != "why.id" {
+		t.Fatal("missing attrs:", attrs)
+	}
+
+	elems, unk, _, err := UnknownXMLTagsSplit(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(elems) != 0 || len(unk) != 1 || unk[0] != "why.@attr" {
+		t.Fatal("unknown:", elems, unk)
+	}
+	mems, _, err = MissingXMLTagsStd(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "why.@id" {
+		t.Fatal("missing std:", mems)
+	}
+}
+
+func TestTextKey(t *testing.T) {
+	type note struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+	type test struct {
+		Note note `xml:"note"`
+		Sub  note `xml:"sub"`
+	}
+	data := []byte(`<doc><note lang="en">hello</note><sub>simple</sub></doc>`)
+
+	// default text key with mxj
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "sub.-lang" {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+
+	mems, _, err = MissingXMLTagsStd(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "sub.-lang" {
+		t.Fatal("missing std:", mems)
+	}
+
+	// the chardata is reported with the text key
+	mems, _, err = MissingXMLTags([]byte(`<doc><note lang="en"/><sub>x</sub></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, v := HasTags(mems, "note.#text"); !ok {
+		t.Fatal("missing:", mems, v)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	type test struct {
+		Ok bool `xml:"ok"`
+	}
+	data := []byte(`<doc><ok>true</ok>` + strings.Repeat("<x>1</x>", 20) + `<bad></doc>`)
+
+	_, _, err := MissingXMLTags(data, test{})
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("not a ParseError: %T %v", err, err)
+	}
+	if pe.Err == nil || errors.Unwrap(err) != pe.Err {
+		t.Fatal("Err:", pe.Err)
+	}
+	if pe.Root != "doc" || pe.Len != len(data) || string(pe.Prefix) != string(data[:ParseErrorPrefixLen]) {
+		t.Fatalf("fields: %q %d %q", pe.Root, pe.Len, pe.Prefix)
+	}
+	if !strings.HasPrefix(err.Error(), "parsing XML data with root doc: ") {
+		t.Fatal("Error:", err)
+	}
+
+	// no root element
+	_, _, err = UnknownXMLTags([]byte(`no XML`), test{})
+	if !errors.As(err, &pe) {
+		t.Fatalf("not a ParseError: %T %v", err, err)
+	}
+	if pe.Root != "" || pe.Len != 6 || string(pe.Prefix) != "no XML" {
+		t.Fatalf("no root: %q %d %q", pe.Root, pe.Len, pe.Prefix)
+	}
+}
+
+func TestSetNameNormalizer(t *testing.T) {
+	type test struct {
+		CreatedAt string `xml:"createdAt"`
+		UserID    string `xml:"user_id,attr"`
+		Name      string `xml:"name"`
+	}
+	data := []byte(`<doc user-id="1"><created-at>now</created-at><other_tag/></doc>`)
+
+	mems, _, _ := MissingXMLTags(data, test{})
+	if len(mems) != 3 {
+		t.Fatal("exact missing:", mems)
+	}
+
+	SetNameNormalizer(func(s string) string {
+		return strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s))
+	})
+	defer SetNameNormalizer(nil)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "name" {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "other_tag" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// the attribute prefix isn't normalized
+	data = []byte(`<doc><user-id>1</user-id><createdAt>now</createdAt><name/></doc>`)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 1 || mems[0] != "-user_id" {
+		t.Fatal("attr missing:", mems)
+	}
+	tags, _, _ = UnknownXMLTags(data, test{})
+	if len(tags) != 1 || tags[0] != "user-id" {
+		t.Fatal("attr unknown:", tags)
+	}
+}
+
+func TestSetSubtreesToIgnore(t *testing.T) {
+	type legacy struct {
+		Version string `xml:"version"`
+		Format  string `xml:"format"`
+	}
+	type test struct {
+		ID     string `xml:"id"`
+		Legacy legacy `xml:"legacy"`
+	}
+	data := []byte(`<doc>
+	  <id>1</id>
+	  <legacy>
+	    <old>a</old>
+	    <older><oldest>b</oldest></older>
+	    <obsolete x="y"/>
+	  </legacy>
+	  <extra/>
+	</doc>`)
+
+	mems, _, _ := MissingXMLTags(data, test{})
+	tags, _, _ := UnknownXMLTags(data, test{})
+	if len(mems) != 2 || len(tags) != 4 {
+		t.Fatal("not ignored:", mems, tags)
+	}
+
+	SetSubtreesToIgnore("legacy")
+	defer SetSubtreesToIgnore()
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err = UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "extra" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// the member itself isn't missing
+	mems, _, _ = MissingXMLTags([]byte(`<doc><id>1</id></doc>`), test{})
+	if len(mems) != 0 {
+		t.Fatal("member:", mems)
+	}
+}
+
+func TestSetCaseInsensitive(t *testing.T) {
+	type test struct {
+		Name string `xml:"name"`
+		ID   string `xml:"id,attr"`
+		Sub  struct {
+			Value string `xml:"value"`
+		} `xml:"sub"`
+	}
+	data := []byte(`<doc ID="1"><NAME>x</NAME><Sub><Value>2</Value></Sub></doc>`)
+
+	// the encoding/xml decoder requires an exact match
+	mems, _, _ := MissingXMLTags(data, test{})
+	if len(mems) != 4 {
+		t.Fatal("exact:", mems)
+	}
+
+	SetCaseInsensitive()
+	defer SetCaseInsensitive(false)
+	if !caseInsensitive {
+		t.Fatal("SetCaseInsensitive() didn't toggle to true")
+	}
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+
+	// the attribute prefix is still significant
+	tags, _, _ = UnknownXMLTags([]byte(`<doc><Id>1</Id><name/><sub/></doc>`), test{})
+	if len(tags) != 1 || tags[0] != "Id" {
+		t.Fatal("attr:", tags)
+	}
+}
+
+func TestSetKeyMapper(t *testing.T) {
+	type item struct {
+		Price string `xml:"price"`
+		Qty   string `xml:"qty"`
+	}
+	type test struct {
+		ID    string `xml:"id,attr"`
+		Addr2 string `xml:"addr2"`
+		Item  item   `xml:"item"`
+	}
+	versioned := []byte(`<doc id_v1="1"><addr2>x</addr2><item><price_v2>1</price_v2><qty>2</qty><other_v3/></item></doc>`)
+	data := versioned
+
+	mems, _, _ := MissingXMLTags(data, test{})
+	if len(mems) != 2 {
+		t.Fatal("exact missing:", mems)
+	}
+
+	suffix := regexp.MustCompile(`_v[0-9]+$`)
+	SetKeyMapper(func(k string) string {
+		return suffix.ReplaceAllString(k, "")
+	})
+	defer SetKeyMapper(nil)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	// the reported tag is the original one
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "item.other_v3" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// the mapper isn't applied to the member tags
+	SetKeyMapper(func(k string) string {
+		return strings.TrimRight(k, "0123456789")
+	})
+	data = []byte(`<doc id="1"><addr>x</addr><item><price>1</price><qty>2</qty></item></doc>`)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 1 || mems[0] != "addr2" {
+		t.Fatal("member tag:", mems)
+	}
+
+	v := NewValidator(reflect.TypeOf(test{}), WithKeyMapper(nil))
+	if mems, _, _ = v.Missing(versioned); len(mems) != 2 {
+		t.Fatal("validator:", mems)
+	}
+}
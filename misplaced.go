@@ -0,0 +1,104 @@
+// misplaced.go - check for attributes that are elements and vice versa
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// MisplacedXMLTags returns the dot-notation XML tags in the XML data that are
+// the wrong kind relative to the struct 'val' - an element for a member with an
+// `xml:"name,attr"` tag, or an attribute for a member with an element tag.  The
+// tags are as they appear in the XML data - as reported by UnknownXMLTags - so an
+// attribute has the attribute prefix, see SetAttrPrefix; e.g., for the struct
+// member `xml:"id,attr"` and the XML data <doc><id>1</id></doc> "id" is returned.
+// Such tags are otherwise reported by both MissingXMLTags and UnknownXMLTags without
+// any explanation.  If the XML data can't be decoded, nil is returned.
+func MisplacedXMLTags(b []byte, val interface{}) []string {
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	for _, v = range m {
+		break
+	}
+	var s tagList
+	checkMisplaced(v, reflect.ValueOf(val), &s, "", make(map[string]bool))
+	return s.tags
+}
+
+// checkMisplaced walks the struct value 'val' and the mxj.Map value 'mv' in
+// parallel, as checkMembers does; 'seen' prevents reporting a tag more than once.
+func checkMisplaced(mv interface{}, val reflect.Value, s *tagList, cmem string, seen map[string]bool) {
+	if s.tooDeep(cmem) {
+		return
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() || isLeafType(val.Type()) {
+		return
+	}
+	typ := val.Type()
+	if typ.Kind() == reflect.Slice {
+		list, ok := mv.([]interface{})
+		if !ok {
+			list = []interface{}{mv}
+		}
+		sval := reflect.New(typ.Elem())
+		for _, v := range list {
+			checkMisplaced(v, sval, s, cmem, seen)
+		}
+		return
+	}
+	o := s.opt()
+	mm, ok := mv.(map[string]interface{})
+	if typ.Kind() != reflect.Struct || !ok || o.attrPrefix == "" {
+		return
+	}
+
+	join := func(k string) string {
+		if cmem == "" {
+			return k
+		}
+		return cmem + "." + k
+	}
+	ts := getTypeSpec(typ, o.attrPrefix)
+	for _, field := range ts.fields {
+		if s.done {
+			return
+		}
+		if field.skip || field.chardata || len(field.tag) > 1 {
+			continue
+		}
+		// the key the member would have if it were the other kind
+		other := o.attrPrefix + field.key
+		if field.attr {
+			other = strings.TrimPrefix(field.key, o.attrPrefix)
+		}
+		if _, ok := mm[field.key]; !ok {
+			// the other kind may be a member, too - `xml:"v"` and `xml:"v,attr"`
+			if _, ok = ts.keys[other]; ok {
+				continue
+			}
+			if _, ok = mm[other]; ok && !seen[join(other)] {
+				seen[join(other)] = true
+				s.add(join(other), typ)
+			}
+			continue
+		}
+		if !field.attr {
+			checkMisplaced(mm[field.key], field.value(val), s, join(field.key), seen)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMisplacedXMLTags(t *testing.T) {
+	type sub struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+	type item struct {
+		Code string `xml:"code,attr"`
+	}
+	type test struct {
+		ID    string  `xml:"id,attr"`
+		Name  string  `xml:"name"`
+		Sub   sub     `xml:"sub"`
+		Items []*item `xml:"item"`
+		Skip  string  `xml:"-"`
+	}
+
+	// an element for an attribute member
+	data := []byte(`<doc><id>1</id><name>x</name><sub><lang>en</lang>hi</sub></doc>`)
+	tags := MisplacedXMLTags(data, test{})
+	if !reflect.DeepEqual(tags, []string{"id", "sub.lang"}) {
+		t.Fatal("element:", tags)
+	}
+
+	// an attribute for an element member
+	data = []byte(`<doc id="1" name="x"><sub lang="en">hi</sub><item><code>a</code></item><item><code>b</code></item></doc>`)
+	tags = MisplacedXMLTags(data, &test{})
+	if !reflect.DeepEqual(tags, []string{"-name", "item.code"}) {
+		t.Fatal("attribute:", tags)
+	}
+
+	// nothing misplaced, even if missing or unknown
+	data = []byte(`<doc id="1"><sub/><other>y</other></doc>`)
+	if tags = MisplacedXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("none:", tags)
+	}
+	if tags = MisplacedXMLTags([]byte(`<doc>`), test{}); tags != nil {
+		t.Fatal("bad XML:", tags)
+	}
+}
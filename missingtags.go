@@ -6,6 +6,7 @@
 package checkxml
 
 import (
+	"context"
 	"io"
 	"reflect"
 	"strings"
@@ -60,23 +61,65 @@ import (
 // tags - this might be useful if you want to find the "omitempty" members that
 // are not set by decoding the XML data..
 //
+// The attributes of the XML root element, e.g., <doc version="2">, are checked
+// against the `xml:"name,attr"` members of 'val' - as are the attributes of any
+// other element - and are reported by UnknownXMLTags, e.g., "-version", if there
+// is no such member.
+//
+// If 'val' is a slice, e.g., []Item, each child element of the XML root element
+// is checked against the slice element type, and the tags are relative to the
+// child elements; e.g., for <items><item><id>1</id></item><item/></items>,
+// "item.id" is returned if Item has an `xml:"id"` member.
+//
+// A struct member of type interface{} is treated as a catch-all for its element:
+// it's never reported as missing, and no tags in its element are reported by
+// UnknownXMLTags.  (The encoding/xml package doesn't decode into such members;
+// they're usually placeholders for XML data that's handled elsewhere.)
+//
+// If the XML root element has no child elements or attributes - a simple element,
+// e.g., <doc>text</doc> or <doc/> - none of the members of 'val' can be set, and
+// the name of the type of 'val' is returned as the single missing tag with a nil
+// error.  UnknownXMLTags returns no tags and a nil error for such XML data.  For
+// a struct member whose element is absent or simple, e.g., <sub>text</sub>, each
+// of the members of its struct is reported - "sub.name", etc. - rather than the
+// name of the struct type.
+//
 // NOTE: dot-notation XML tag values returned by MissingXMLTags use the
 // struct member `xml` tag or the public field name if there is no `xml` tag.
 // This allows the members of the returned slice to be used to directly manipulate a mxj.Map
 // representation of the XML data if it is available.
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
 //
-// KNOWN BUG: recursive struct definitions are NOT handled correctly.  E.g.:
-//		type MyStruct struct {
-//		   More *[]MyStruct
+// Recursive struct definitions are checked to the depth of the XML data.  E.g.:
+//		type Category struct {
+//			Name string     `xml:"name"`
+//			Sub  []Category `xml:"sub"`
 //		}
+// For <doc><sub><name>a</name></sub></doc>, "sub.sub" and "sub.sub.name" are
+// returned - the members of the absent inner <sub> element are reported, but not
+// those of the elements it might have had in turn.
 func MissingXMLTags(b []byte, val interface{}) ([]string, string, error) {
-	var s []string
-
-	m, err := mxj.NewMapXml(b)
+	s, root, err := missingXMLTags(b, val, nil)
 	if err != nil {
 		return nil, "", err
 	}
+	return s.tags, root, s.err
+}
+
+// MissingXMLTagsMap returns the mxj.Map - map[string]interface{} - representation of the XML data
+// and the XML root tag in addition to the missing XML tags.
+// (See github.com/clbanning/mxj documentation of mxj.Map type.)
+// If 'cast' is given, cast[0] is passed to mxj.NewMapXml for the call in place of
+// the SetMxjCast setting - if true, the mxj.Map values are cast as float64 or bool
+// if possible.  It's the only mxj decoding option that's forwarded; the others,
+// e.g., mxj.PrependAttrWithHyphen, are global to the mxj package.
+func MissingXMLTagsMap(b []byte, val interface{}, cast ...bool) ([]string, mxj.Map, string, error) {
+	var s tagList
+
+	m, err := mxj.NewMapXml(skipBOM(b), castFlag(cast))
+	if err != nil {
+		return nil, m, "", newParseError(err, b)
+	}
 	// strip off the root value
 	var root string
 	var v interface{}
@@ -87,25 +130,241 @@ func MissingXMLTags(b []byte, val interface{}) ([]string, string, error) {
 	vv, ok := v.(map[string]interface{})
 	if !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// return the name of the value passed if not a map[string]interface{} value
-			s = append(s, reflect.ValueOf(val).Type().Name())
-			return s, root, nil
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return s.tags, m, root, err
 		}
 	}
 
 	checkMembers(vv, reflect.ValueOf(val), &s, "")
+	s.checkRoot(root, b, val)
+	return s.tags, m, root, s.err
+}
+
+// MissingXMLTagsMapRaw is MissingXMLTagsMap with the XML data, 'b', returned as the
+// raw XML data, as MissingXMLTagsReaderMapRaw returns it; 'b' is returned even if
+// it can't be decoded.
+func MissingXMLTagsMapRaw(b []byte, val interface{}, cast ...bool) ([]string, mxj.Map, string, []byte, error) {
+	tags, m, root, err := MissingXMLTagsMap(b, val, cast...)
+	return tags, m, root, b, err
+}
+
+// MissingXMLTagsType is MissingXMLTags for a struct definition that is only
+// available as a reflect.Type - e.g., reflect.TypeOf(MyStruct{}) - rather than
+// as a value.  If 't' is a pointer type, the struct it points to is used.
+func MissingXMLTagsType(b []byte, t reflect.Type) ([]string, string, error) {
+	return MissingXMLTags(b, zeroValue(t))
+}
+
+// EmptyRequiredXMLTags returns a slice of the dot-notation XML tags for struct
+// members that are required - they don't have an "omitempty" tag, see also
+// IgnoreOmitemptyTag and SetOmitemptyRequired - and whose XML element or attribute
+// is present in the XML data but has no value; e.g., <elem/> or <elem>  </elem>.
+// This distinguishes content absence from the schema absence reported by
+// MissingXMLTags; a tag is never reported by both functions.
+//
+// If TreatEmptyAsMissing(true) has been called, such members are reported by
+// MissingXMLTags instead and EmptyRequiredXMLTags returns no tags.
+func EmptyRequiredXMLTags(b []byte, val interface{}) ([]string, string, error) {
+	s, root, err := missingXMLTags(b, val, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.empty, root, s.err
+}
+
+// Coverage returns the number of struct members of 'val' that are checked by
+// MissingXMLTags - 'defined' - and how many of those are set by the XML data -
+// 'matched' - so that matched/defined is the completeness of the XML data.  The
+// count is consistent with MissingXMLTags: members that are ignored, see
+// SetMembersToIgnore, or are optional, see IgnoreOmitemptyTag, aren't counted;
+// and nested struct members and each element of a list are counted.  So
+// defined-matched is the number of missing tags reported by MissingXMLTags.
+func Coverage(b []byte, val interface{}) (defined, matched int, root string, err error) {
+	s, root, err := missingXMLTags(b, val, nil)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	if s.checked < len(s.tags) {
+		// the XML data isn't a complex element - see missingXMLTags
+		return s.checked, 0, root, s.err
+	}
+	return s.checked, s.checked - len(s.tags), root, s.err
+}
+
+// PresentXMLTags returns a slice of the dot-notation XML tags for struct members
+// of 'val' that are set by the XML data - the complement of MissingXMLTags for
+// the members it checks, along with any "omitempty" members that are present.
+// Members that are ignored, see SetMembersToIgnore, aren't reported and a tag is
+// listed once even if it occurs in several elements of a list.
+func PresentXMLTags(b []byte, val interface{}) ([]string, string, error) {
+	s, root, err := missingXMLTags(b, val, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	var tags []string
+	seen := make(map[string]bool, len(s.present))
+	for _, t := range s.present {
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+	return tags, root, s.err
+}
+
+// MissingTag describes a struct member that will not be set by unmarshaling the
+// XML data. Path is the dot-notation XML tag as reported by MissingXMLTags, Kind
+// is the reflect.Kind of the member - after dereferencing any pointer - and Type
+// is the member's type as a string, e.g., "*int" or "[]string".  Depth is the
+// number of elements in Path - 1 for a member of the root element; see TagDepth.
+type MissingTag struct {
+	Path  string
+	Kind  reflect.Kind
+	Type  string
+	Depth int
+}
+
+// MissingXMLTagsDetailed is MissingXMLTags with the kind and type of each
+// missing struct member reported along with its dot-notation XML tag.
+func MissingXMLTagsDetailed(b []byte, val interface{}) ([]MissingTag, string, error) {
+	tags, root, err := missingXMLTags(b, val, nil)
+	if err != nil {
+		return nil, root, err
+	}
+	return tags.missingTags(), root, tags.err
+}
+
+// missingXMLTags does the work for MissingXMLTags and MissingXMLTagsDetailed,
+// leaving the result as a tagList.
+// If 'o' is nil, the package settings are used.
+func missingXMLTags(b []byte, val interface{}, o *options) (*tagList, string, error) {
+	s := &tagList{opts: o}
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip off the root value
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	vv, ok := v.(map[string]interface{})
+	if !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return s, root, err
+		}
+	}
+
+	checkMembers(vv, reflect.ValueOf(val), s, "")
+	s.checkRoot(root, b, val)
 	return s, root, nil
 }
 
-// MissingXMLTagsMap returns the mxj.Map - map[string]interface{} - representation of the XML data
-// and the XML root tag in addition to the missing XML tags.
-// (See github.com/clbanning/mxj documentation of mxj.Map type.)
-func MissingXMLTagsMap(b []byte, val interface{}) ([]string, mxj.Map, string, error) {
-	var s []string
+// missingTags converts the tagList from checkMembers into a []MissingTag.
+func (t *tagList) missingTags() []MissingTag {
+	if len(t.tags) == 0 {
+		return nil
+	}
+	mt := make([]MissingTag, len(t.tags))
+	for i, tag := range t.tags {
+		typ := t.types[i]
+		mt[i].Path = tag
+		mt[i].Type = typ.String()
+		mt[i].Depth = TagDepth(tag)
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		mt[i].Kind = typ.Kind()
+	}
+	return mt
+}
+
+// TagPair is a missing tag as both the dot-notation XML tag, TagPath, as reported
+// by MissingXMLTags, and the dot-notation path of the struct member names,
+// FieldPath; e.g., {"elem2.another", "Elem2.Another"}.  The paths have the same
+// number of elements.
+type TagPair struct {
+	TagPath   string
+	FieldPath string
+}
 
-	m, err := mxj.NewMapXml(b, mxjCast)
+// MissingXMLTagsBoth is MissingXMLTags with the struct member names path reported
+// along with each missing tag; see TagPair.
+func MissingXMLTagsBoth(b []byte, val interface{}) ([]TagPair, string, error) {
+	s, root, err := missingXMLTags(b, val, nil)
 	if err != nil {
-		return nil, m, "", err
+		return nil, "", err
+	}
+	var tp []TagPair
+	for _, tag := range s.tags {
+		tp = append(tp, TagPair{tag, fieldPath(reflect.TypeOf(val), tag, s.opt())})
+	}
+	return tp, root, s.err
+}
+
+// fieldPath returns the struct member names path for the dot-notation tag 'tag'
+// of a member of the struct type 'typ'.  An element of 'tag' that isn't a member
+// tag - e.g., the child element of a top-level list - is kept as is.
+func fieldPath(typ reflect.Type, tag string, o *options) string {
+	path := strings.Split(tag, ".")
+	for i, seg := range path {
+		if typ != nil {
+			typ = memberType(typ)
+		}
+		if typ == nil || typ.Kind() != reflect.Struct {
+			continue
+		}
+		var next reflect.Type
+		for _, fs := range getTypeSpec(typ, o.attrPrefix).fields {
+			k := fs.key
+			if fs.chardata {
+				k = textKey
+			}
+			if !fs.skip && k == seg {
+				path[i] = typ.FieldByIndex(fs.index).Name
+				next = typ.FieldByIndex(fs.index).Type
+				break
+			}
+		}
+		if next != nil || i > 0 {
+			typ = next
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// MissingXMLAttrs is MissingXMLTags restricted to the struct members that have
+// an "attr" XML tag - `xml:"name,attr"`.  The attribute tags are reported without
+// the attribute prefix, e.g., "elem.id" rather than "elem.-id", so they can be reported
+// separately from the missing element tags.
+func MissingXMLAttrs(b []byte, val interface{}) ([]string, string, error) {
+	s, root, err := missingXMLTags(b, val, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	_, attrs := splitTags(s.tags, s.opt().attrPrefix)
+	for i, a := range attrs {
+		n := strings.LastIndex(a, ".") + 1
+		attrs[i] = a[:n] + a[n+len(s.opt().attrPrefix):]
+	}
+	return attrs, root, s.err
+}
+
+// WalkMissingXMLTags calls fn with each missing XML tag, in dot-notation, as it is
+// found rather than accumulating them in a slice.  If fn returns false the scan of
+// the XML data is terminated.  The XML root tag is returned.
+func WalkMissingXMLTags(b []byte, val interface{}, fn func(tag string) bool) (string, error) {
+	s := tagList{fn: fn}
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return "", newParseError(err, b)
 	}
 	// strip off the root value
 	var root string
@@ -117,14 +376,15 @@ func MissingXMLTagsMap(b []byte, val interface{}) ([]string, mxj.Map, string, er
 	vv, ok := v.(map[string]interface{})
 	if !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// return the name of the value passed if not a map[string]interface{} value
-			s = append(s, reflect.ValueOf(val).Type().Name())
-			return s, m, root, nil
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return root, err
 		}
 	}
 
 	checkMembers(vv, reflect.ValueOf(val), &s, "")
-	return s, m, root, nil
+	s.checkRoot(root, b, val)
+	return root, s.err
 }
 
 // ================= io.Reader functions ...
@@ -132,10 +392,19 @@ func MissingXMLTagsMap(b []byte, val interface{}) ([]string, mxj.Map, string, er
 // MissingXMLTagsReader consumes the XML data from an io.Reader and returns the XML tags
 // that are missing with respect to the struct 'val' and the XML root tag.
 func MissingXMLTagsReader(r io.Reader, val interface{}) ([]string, string, error) {
-	var s []string
+	return MissingXMLTagsReaderContext(context.Background(), r, val)
+}
+
+// MissingXMLTagsReaderContext is MissingXMLTagsReader with a Context.  If the Context is done
+// while the XML data is being read or checked, ctx.Err() is returned.  See also
+// SetMaxReadSize.
+func MissingXMLTagsReaderContext(ctx context.Context, r io.Reader, val interface{}) ([]string, string, error) {
+	s := tagList{ctx: ctx}
+	cr := newCtxReader(ctx, r, s.opt().maxReadSize)
 
-	m, err := mxj.NewMapXmlReader(r)
+	m, err := mxj.NewMapXmlReader(cr)
 	if err != nil {
+		err = cr.readErr(err)
 		return nil, "", err
 	}
 	// strip off the root value
@@ -148,25 +417,36 @@ func MissingXMLTagsReader(r io.Reader, val interface{}) ([]string, string, error
 	vv, ok := v.(map[string]interface{})
 	if !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// return the name of the value passed if not a map[string]interface{} value
-			s = append(s, reflect.ValueOf(val).Type().Name())
-			return s, root, nil
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return s.tags, root, err
 		}
 	}
 
 	checkMembers(vv, reflect.ValueOf(val), &s, "")
-	return s, root, nil
+	s.checkRoot(root, nil, val)
+	return s.tags, root, s.err
 }
 
 // MissingXMLTagsReaderMap consumes the XML data from an io.Reader and returns the
 // mxj.Map - map[string]interface{} - representation of the XML data and the root
 // XML tag in addition to the missing XML tags.
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
-func MissingXMLTagsReaderMap(r io.Reader, val interface{}) ([]string, mxj.Map, string, error) {
-	var s []string
+// 'cast' is as for MissingXMLTagsMap.
+func MissingXMLTagsReaderMap(r io.Reader, val interface{}, cast ...bool) ([]string, mxj.Map, string, error) {
+	return MissingXMLTagsReaderMapContext(context.Background(), r, val, cast...)
+}
+
+// MissingXMLTagsReaderMapContext is MissingXMLTagsReaderMap with a Context.  If the Context is done
+// while the XML data is being read or checked, ctx.Err() is returned.  See also
+// SetMaxReadSize.
+func MissingXMLTagsReaderMapContext(ctx context.Context, r io.Reader, val interface{}, cast ...bool) ([]string, mxj.Map, string, error) {
+	s := tagList{ctx: ctx}
+	cr := newCtxReader(ctx, r, s.opt().maxReadSize)
 
-	m, err := mxj.NewMapXmlReader(r, mxjCast)
+	m, err := mxj.NewMapXmlReader(cr, castFlag(cast))
 	if err != nil {
+		err = cr.readErr(err)
 		return nil, m, "", err
 	}
 	// strip off the root value
@@ -179,25 +459,37 @@ func MissingXMLTagsReaderMap(r io.Reader, val interface{}) ([]string, mxj.Map, s
 	vv, ok := v.(map[string]interface{})
 	if !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// return the name of the value passed if not a map[string]interface{} value
-			s = append(s, reflect.ValueOf(val).Type().Name())
-			return s, m, root, nil
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return s.tags, m, root, err
 		}
 	}
 
 	checkMembers(vv, reflect.ValueOf(val), &s, "")
-	return s, m, root, nil
+	s.checkRoot(root, nil, val)
+	return s.tags, m, root, s.err
 }
 
 // MissingXMLTagsReaderMapRaw consumes the XML data from an io.Reader and returns
 // the mxj.Map - map[string]interface{} - representation of the XML data and the raw XML data
 // that was read from the io.Reader in addition to the missing XML tags.
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
-func MissingXMLTagsReaderMapRaw(r io.Reader, val interface{}) ([]string, mxj.Map, string, []byte, error) {
-	var s []string
+// 'cast' is as for MissingXMLTagsMap.
+func MissingXMLTagsReaderMapRaw(r io.Reader, val interface{}, cast ...bool) ([]string, mxj.Map, string, []byte, error) {
+	return MissingXMLTagsReaderMapRawContext(context.Background(), r, val, cast...)
+}
+
+// MissingXMLTagsReaderMapRawContext is MissingXMLTagsReaderMapRaw with a Context.  If the Context is done
+// while the XML data is being read or checked, ctx.Err() is returned.  See also
+// SetMaxReadSize.
+// The raw XML data that was read before cancellation is still returned.
+func MissingXMLTagsReaderMapRawContext(ctx context.Context, r io.Reader, val interface{}, cast ...bool) ([]string, mxj.Map, string, []byte, error) {
+	s := tagList{ctx: ctx}
+	cr := newCtxReader(ctx, r, s.opt().maxReadSize)
 
-	m, raw, err := mxj.NewMapXmlReaderRaw(r, mxjCast)
+	m, raw, err := mxj.NewMapXmlReaderRaw(cr, castFlag(cast))
 	if err != nil {
+		err = cr.readErr(err)
 		return nil, m, "", raw, err
 	}
 	// strip off the root value
@@ -210,29 +502,56 @@ func MissingXMLTagsReaderMapRaw(r io.Reader, val interface{}) ([]string, mxj.Map
 	vv, ok := v.(map[string]interface{})
 	if !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// return the name of the value passed if not a map[string]interface{} value
-			s = append(s, reflect.ValueOf(val).Type().Name())
-			return s, m, root, raw, nil
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return s.tags, m, root, raw, err
 		}
 	}
 
 	checkMembers(vv, reflect.ValueOf(val), &s, "")
-	return s, m, root, raw, nil
+	s.checkRoot(root, raw, val)
+	return s.tags, m, root, raw, s.err
+}
+
+// scalarRoot handles XML data whose root element is a simple element, which has
+// none of the members of 'val': the name of the type of 'val' is reported, or
+// ErrRootKind is returned if StrictRootKind(true) has been called.
+func (t *tagList) scalarRoot(val interface{}) error {
+	if t.opt().strictRootKind {
+		return ErrRootKind
+	}
+	t.add(reflect.TypeOf(val).Name(), reflect.TypeOf(val))
+	return nil
 }
 
 // ================== where the work is done ...
 
 // cmem is the parent struct member for nested structs
-func checkMembers(mv interface{}, val reflect.Value, s *[]string, cmem string) {
-	// 1. Convert any pointer value.
+func checkMembers(mv interface{}, val reflect.Value, s *tagList, cmem string) {
+	if s.tooDeep(cmem) || s.canceled() {
+		return
+	}
+	// 1. Convert any pointer value.  A nil pointer - e.g., a *[]Item member - is
+	//    replaced by a pointer to a zero value if there's XML data to check it against.
 	if val.Kind() == reflect.Ptr {
+		if val.IsNil() && mv != nil {
+			val = reflect.New(val.Type().Elem())
+		}
 		val = reflect.Indirect(val)
 	}
-	// zero Value?
+	// zero Value? - e.g., a nil *int with no XML data, which the caller has
+	// already recorded as missing if it's required.
 	if !val.IsValid() {
 		return
 	}
 	typ := val.Type()
+	// Values decoded by UnmarshalText or UnmarshalXML, like time.Time, are leaves.
+	if isLeafType(typ) {
+		return
+	}
+	if cmem == "" {
+		s.applyRegistered(typ)
+	}
 
 	// 2. If its a slice then 'mv' should hold a []interface{} value.
 	//    Loop through the members of 'mv' and see that they are valid relative
@@ -245,6 +564,19 @@ func checkMembers(mv interface{}, val reflect.Value, s *[]string, cmem string) {
 		// Slice may be nil, so create a Value of it's type
 		// 'mv' must be of type []interface{}. If it isn't coerce it.
 		sval := reflect.New(tval)
+		// 2.0. A top-level list - 'val' is a slice - is checked against the
+		//      child elements of the root element.
+		if cmem == "" {
+			for _, c := range rootChildren(mv, s.opt()) {
+				for _, cv := range c.list {
+					if s.done {
+						return
+					}
+					checkMembers(cv, sval, s, c.tag)
+				}
+			}
+			return
+		}
 		var slice []interface{}
 		var ok bool
 		slice, ok = mv.([]interface{})
@@ -254,6 +586,9 @@ func checkMembers(mv interface{}, val reflect.Value, s *[]string, cmem string) {
 		// 2.1. Check members of XML list array.
 		//      This forces all of them to be regular and w/o typos in key labels.
 		for _, sl := range slice {
+			if s.done {
+				return
+			}
 			checkMembers(sl, sval, s, cmem)
 		}
 		return // done with reflect.Slice value
@@ -263,136 +598,157 @@ func checkMembers(mv interface{}, val reflect.Value, s *[]string, cmem string) {
 	if typ.Kind() != reflect.Struct {
 		return // just ignore it - don't look for k:v pairs
 	}
-	// 3b. map value must represent k:v pairs
-	mm, ok := mv.(map[string]interface{})
-	if !ok {
-		*s = append(*s, cmem+typ.Name())
-		return
-	}
-	// 3c. NOTE: Don't coerce keys to lower case.
-	//     XML decoder requires that XML tag matches 
-	//     struct member name (or tag) exactly.
-	mkeys := make(map[string]interface{}, len(mm))
-	for k, v := range mm {
-		mkeys[k] = v
-	}
-
-	// 4. Build the list of struct field name:value
-	//    We make every key (field) label look like an exported label - "Fieldname".
-	//    If there is a XML tag it is used instead of the field label, and saved to
-	//    insure that the spec'd tag matches the XML tag exactly.
-	type fieldSpec struct {
-		name      string
-		val       reflect.Value
-		tag       []string
-		omitempty bool
-	}
-	fieldCnt := val.NumField()
-	var fields []*fieldSpec // use a list so members are in sequence
-	var attr bool
-	var tagvals string
-	var tags []string
-	var tag []string
-	var oempty bool
-	for i := 0; i < fieldCnt; i++ {
-		if len(typ.Field(i).PkgPath) > 0 {
-			continue // field is NOT exported
-		}
-		// Ignore xml.Name type fields - they don't appear in the map mm.
-		// The root label is handed in as "key" in the initial call.
-		if typ.Field(i).Type.Name() == "Name" && typ.Field(i).Type.PkgPath() == "encoding/xml" {
-			continue
-		}
-		tagvals = typ.Field(i).Tag.Get("xml")
-		tags = strings.Split(tagvals, ",")
-		tag = strings.Split(tags[0], ">")
-		// Fields with "-" may or maynot be in the the XML data.
-		// don't even bother to check that the Field occurs.
-		if tag[0] == "-" {
-			continue
+	// 3b. map value must represent k:v pairs; if it doesn't - the element
+	//     is missing or is a simple element - then none of the members are set.
+	mm, _ := mv.(map[string]interface{})
+	// 3b'. With no XML data, the members of a recursive type - e.g., the Sub
+	//      member of `type Category struct{ Sub []Category }` - are reported
+	//      once, for the outermost absent element, rather than without end.
+	if mv == nil {
+		if s.absent[typ] {
+			return
 		}
-		// Scan rest of tags for "omitempty" and "attr".
-		// If omitempty occurs we will allow it to occur or not
-		// unless the omitemptyOK flag is false, then we strictly
-		// check that the kwy is there.
-		oempty, attr = false, false
-		for _, v := range tags[1:] {
-			if v == "omitempty" {
-				oempty = true
-			}
-			if v == "attr" {
-				attr = true
-			}
-		}
-		// If attr==true then the mm key will be prepended with "-"
-		// so the Field name and the 'tag' value must be prepended with "-"
-		// to match the decoded value.
-		// NOTE: the xml decoder requires that elem/attr tags match exactly
-		// the public member name or its xml tag label; unlike json decoder
-		// there is no coersion of lower case element tags to public
-		// member names.
-		switch attr {
-		case false:
-			if tag[0] == "" {
-				fields = append(fields, &fieldSpec{typ.Field(i).Name, val.Field(i), tag, oempty})
-			} else {
-				fields = append(fields, &fieldSpec{typ.Field(i).Name, val.Field(i), tag, oempty})
-			}
-		case true:
-			if tag[0] == "" {
-				fields = append(fields, &fieldSpec{"-" + typ.Field(i).Name, val.Field(i), tag, oempty})
-			} else {
-				tag[0] = "-" + tag[0]
-				fields = append(fields, &fieldSpec{"-" + typ.Field(i).Name, val.Field(i), tag, oempty})
-			}
+		if s.absent == nil {
+			s.absent = make(map[reflect.Type]bool)
 		}
+		s.absent[typ] = true
+		defer delete(s.absent, typ)
 	}
+	// 3c. NOTE: Don't coerce keys to lower case.
+	//     XML decoder requires that XML tag matches 
+	//     struct member name (or tag) exactly.  Keys are only compared
+	//     without regard to case if SetCaseInsensitive has been called;
+	//     see the member lookup in 5.
+
+	// 4. Get the list of struct field specs - the xml tag, if there is one, is
+	//    used instead of the field label to insure that the spec'd tag matches
+	//    the XML tag exactly. (See newTypeSpec().)
+	o := s.opt()
+	ts := getTypeSpec(typ, o.attrPrefix)
+	// A flat struct - e.g., for a message with only simple elements - has no
+	// members to descend into, unless the depth of the members is checked.
+	flat := ts.flat && o.maxDepth == 0
 
 	// 5. check that field names/tags have corresponding map key
-	// var ok bool
+	var ok bool
 	var v interface{}
 	// var err error
 	cmemdepth := 1
 	if len(cmem) > 0 {
 		cmemdepth = len(strings.Split(cmem, ".")) + 1 // struct hierarchy
 	}
-	var fn string
-	for _, field := range fields {
-		// see if we should use XML tag to lookup map key
-		if len(field.tag[0]) > 0 {
-			fn = field.tag[0]
+	var fn, tkey string
+	var fval reflect.Value
+	var required bool
+	for _, field := range ts.fields {
+		if s.done {
+			return
+		}
+		// Fields with "-" may or maynot be in the the XML data.
+		// don't even bother to check that the Field occurs.
+		if field.skip {
+			continue
+		}
+		// Attributes aren't checked at all if IgnoreAllAttrs has been called.
+		if field.attr && o.ignoreAttrs {
+			continue
+		}
+		// An interface{} member is a catch-all for any XML data - it's never
+		// missing and nothing in its element is unknown; see checkAllTags.
+		if typ.FieldByIndex(field.index).Type.Kind() == reflect.Interface {
+			continue
+		}
+		// the XML tag, if any, is used to lookup map key
+		fn = field.key
+		if field.chardata {
+			fn = textKey
+		}
+		fval = field.value(val)
+		if len(cmem) > 0 {
+			tkey = cmem + "." + fn
 		} else {
-			fn = field.name
+			tkey = fn
+		}
+		if len(o.skipsubtrees) > 0 && inSubtree(tkey, o.skipsubtrees) {
+			continue
+		}
+		if len(o.deprecated) > 0 && matchTag(tkey, o.deprecated) {
+			continue
 		}
-		for _, sm := range skipmembers {
+		for _, sm := range o.skipmembers {
+			smv := sm.path(o.attrPrefix)
+			// "*.<suffix>" values match at any depth
+			if sm.depth == 0 {
+				if globMatch(smv, tkey) {
+					goto next
+				}
+				continue
+			}
 			// skip any skipmembers values that aren't at same depth
 			if cmemdepth != sm.depth {
 				continue
 			}
 			if len(cmem) > 0 {
-				if cmem+"."+fn == sm.val {
+				if cmem+"."+fn == smv {
 					goto next
 				}
-			} else if fn == sm.val {
+			} else if fn == smv {
 				goto next
 			}
 		}
-		v, ok = mkeys[fn]
-		// If map key is missing, then record it
-		// if there's no omitempty tag or we're ignoring  omitempty tag.
-		if !ok && (!field.omitempty || !omitemptyOK) {
-			if len(cmem) > 0 {
-				// *s = append(*s, cmem+"."+field.name)
-				*s = append(*s, cmem+"."+fn)
-			} else {
-				// *s = append(*s, field.name)
-				*s = append(*s, fn)
+		v, ok = mm[fn]
+		if !ok && o.looseNames() && !field.chardata {
+			// if more than one key matches - e.g., <Item> and <ITEM> - use the
+			// first in sort order, so the result doesn't depend on map order
+			nk := o.normalKey(fn)
+			var mk string
+			for k, kv := range mm {
+				if o.dataKey(k) == nk && (!ok || k < mk) {
+					v, ok, mk = kv, true, k
+				}
 			}
 		}
-		if len(cmem) > 0 {
-			checkMembers(v, field.val, s, cmem+"."+fn)
-		} else {
-			checkMembers(v, field.val, s, fn)
+		if !ok && o.attrOrElement && !field.chardata && o.attrPrefix != "" {
+			v, ok = mm[o.otherKind(fn, field.attr)]
+		}
+		if !ok && field.chardata && mm == nil {
+			// a simple element - its value is the character data
+			switch mv.(type) {
+			case string, float64, bool:
+				v, ok = mv, true
+			}
+		}
+		// The member is required if there's no omitempty tag or we're
+		// ignoring omitempty tag.
+		required = !field.omitempty || !o.omitemptyOK || o.omitemptyRequired[tkey]
+		if required {
+			s.checked++
+		}
+		if ok && o.isEmpty(v) {
+			if o.emptyAsMissing {
+				ok = false
+			} else if required {
+				s.empty = append(s.empty, tkey)
+			}
+		}
+		// If map key is missing, then record it if it's required.
+		if !ok && required {
+			s.add(tkey, fval.Type())
+		}
+		if ok {
+			s.present = append(s.present, tkey)
+		}
+		// The members of an absent optional sub-document may not be missing.
+		if !ok && !required && o.suppressOptional {
+			continue
+		}
+		// An absent optional list, or pointer, has no elements whose members
+		// could be missing - e.g., a `xml:"item,omitempty"` []Item member.
+		if !ok && !required && (fval.Kind() == reflect.Slice || fval.Kind() == reflect.Ptr) {
+			continue
+		}
+		if !flat {
+			checkMembers(v, fval, s, tkey)
 		}
 	next:
 	}
@@ -2,8 +2,17 @@ package checkxml
 
 import (
 	"bytes"
+	"context"
+	"encoding/xml"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/clbanning/mxj"
 )
 
 func TestMissingXMLTags(t *testing.T) {
@@ -82,6 +91,32 @@ func TestMissingXMLTagsMap(t *testing.T) {
 	}
 }
 
+func TestMissingXMLTagsMapRaw(t *testing.T) {
+	type test struct {
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	data := []byte("\xef\xbb\xbf<doc><ok>true</ok><why>it's a test</why></doc>")
+	mems, m, root, raw, err := MissingXMLTagsMapRaw(data, test{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 || root != "doc" {
+		t.Fatal("mems:", mems, "root:", root)
+	}
+	if !bytes.Equal(raw, data) {
+		t.Fatal("raw:", string(raw))
+	}
+	if v, _ := m.ValueForPath("doc.ok"); v != true {
+		t.Fatal("cast:", v)
+	}
+
+	data = []byte(`<doc><ok>`)
+	if _, _, _, raw, err = MissingXMLTagsMapRaw(data, test{}); err == nil || !bytes.Equal(raw, data) {
+		t.Fatal("bad data:", err, string(raw))
+	}
+}
+
 func TestMissingXMLTagsReader(t *testing.T) {
 	// fmt.Println("===================== TestMissingXMLTagsReader ...")
 
@@ -164,6 +199,75 @@ func TestMissingXMLTagsReaderMapRaw(t *testing.T) {
 	}
 }
 
+// slowReader is an endless XML document that's read a few bytes at a time.
+type slowReader struct {
+	buf []byte
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	if r.buf == nil {
+		r.buf = []byte("<doc>")
+	} else if len(r.buf) == 0 {
+		r.buf = []byte("<ok>true</ok>")
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func TestMissingXMLTagsReaderContext(t *testing.T) {
+	type test struct {
+		Ok  []bool `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := MissingXMLTagsReaderContext(ctx, &slowReader{}, test{}); err != context.DeadlineExceeded {
+		t.Fatal("reader:", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, _, err := MissingXMLTagsReaderMapContext(ctx, &slowReader{}, test{}); err != context.DeadlineExceeded {
+		t.Fatal("map:", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, _, raw, err := MissingXMLTagsReaderMapRawContext(ctx, &slowReader{}, test{})
+	if err != context.DeadlineExceeded {
+		t.Fatal("raw:", err)
+	}
+	if len(raw) < len("<doc><ok>") || !strings.HasPrefix("<doc>"+strings.Repeat("<ok>true</ok>", len(raw)), string(raw)) {
+		t.Fatal("raw:", string(raw))
+	}
+
+	// the traversal is also canceled
+	type item struct {
+		Val string `xml:"val"`
+	}
+	type items struct {
+		Item []item `xml:"item"`
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	m, _ := mxj.NewMapXml([]byte("<doc>" + strings.Repeat("<item><val>1</val></item>", 1000) + "</doc>"))
+	s := tagList{ctx: ctx}
+	checkMembers(m["doc"], reflect.ValueOf(items{}), &s, "")
+	if s.err != context.Canceled {
+		t.Fatal("traversal:", s.err)
+	}
+
+	data := []byte("<doc>" + strings.Repeat("<ok>true</ok>", 10) + "</doc>")
+
+	// an unfinished Context is the same as the Reader function
+	mems, root, err := MissingXMLTagsReaderContext(context.Background(), bytes.NewReader(data), test{})
+	if err != nil || root != "doc" || len(mems) != 1 || mems[0] != "why" {
+		t.Fatal("background:", mems, root, err)
+	}
+}
+
 func TestMissingXMLTagsSubElements(t *testing.T) {
 	// fmt.Println("===================== TestMissingXMLTagsSubElements ...")
 	type test3 struct {
@@ -274,3 +378,1279 @@ func TestMissingXMLTagsSkipMems(t *testing.T) {
 		t.Fatalf(fmt.Sprintf("missing mems: %d - %#v", len(mems), mems))
 	}
 }
+
+func TestWalkMissingXMLTags(t *testing.T) {
+	type test struct {
+		A string `xml:"a"`
+		B string `xml:"b"`
+		C string `xml:"c"`
+	}
+	data := []byte(`<doc><x>1</x></doc>`)
+
+	var tags []string
+	root, err := WalkMissingXMLTags(data, test{}, func(tag string) bool {
+		tags = append(tags, tag)
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" {
+		t.Fatal("root:", root)
+	}
+	if len(tags) != 1 {
+		t.Fatal("walk didn't stop:", tags)
+	}
+
+	tags = tags[:0]
+	_, err = WalkMissingXMLTags(data, test{}, func(tag string) bool {
+		tags = append(tags, tag)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 {
+		t.Fatal("tags:", tags)
+	}
+}
+
+func TestMissingXMLTagsDetailed(t *testing.T) {
+	type test struct {
+		Name  string   `xml:"name"`
+		Price int      `xml:"price"`
+		Qty   *float64 `xml:"qty"`
+	}
+	data := []byte(`<doc><name>widget</name></doc>`)
+
+	tags, root, err := MissingXMLTagsDetailed(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" {
+		t.Fatal("root:", root)
+	}
+	check := map[string]reflect.Kind{"price": reflect.Int, "qty": reflect.Float64}
+	if len(tags) != len(check) {
+		t.Fatal("tags:", tags)
+	}
+	for _, v := range tags {
+		k, ok := check[v.Path]
+		if !ok {
+			t.Fatal("unexpected tag:", v.Path)
+		}
+		if v.Kind != k {
+			t.Fatalf("%s: kind %s, expected %s", v.Path, v.Kind, k)
+		}
+	}
+	if tags[1].Type != "*float64" {
+		t.Fatal("qty type:", tags[1].Type)
+	}
+}
+
+func TestTreatEmptyAsMissing(t *testing.T) {
+	type sub struct {
+		Note string `xml:"note"`
+	}
+	type test struct {
+		Name  string `xml:"name"`
+		Blank string `xml:"blank"`
+		Empty string `xml:"empty"`
+		Sub   sub    `xml:"sub"`
+	}
+	data := []byte(`<doc>
+		<name> a name </name>
+		<blank>
+		   
+		</blank>
+		<empty/>
+		<sub><note>a note</note></sub>
+	</doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("mems:", mems)
+	}
+
+	TreatEmptyAsMissing(true)
+	defer TreatEmptyAsMissing(false)
+
+	mems, _, err = MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameTags(mems, []string{"blank", "empty"}) {
+		t.Fatal("mems:", mems)
+	}
+
+	if !isEmptyValue(" \n\t  ") || isEmptyValue(" x ") {
+		t.Fatal("isEmptyValue doesn't trim white space")
+	}
+}
+
+func TestMissingXMLAttrs(t *testing.T) {
+	type sub struct {
+		ID   string `xml:"id,attr"`
+		Note string `xml:"note"`
+	}
+	type test struct {
+		Version string `xml:"version,attr"`
+		Name    string `xml:"name"`
+		Sub     sub    `xml:"sub"`
+	}
+	data := []byte(`<doc version="1"><sub><note>a note</note></sub></doc>`)
+
+	attrs, root, err := MissingXMLAttrs(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" {
+		t.Fatal("root:", root)
+	}
+	if len(attrs) != 1 || attrs[0] != "sub.id" {
+		t.Fatal("attrs:", attrs)
+	}
+}
+
+func TestSetRequiredMembers(t *testing.T) {
+	type test struct {
+		ID   string `xml:"id,omitempty"`
+		Memo string `xml:"memo,omitempty"`
+	}
+	data := []byte(`<doc><memo>m</memo></doc>`)
+
+	// "omitempty" members are optional by default
+	if mems, _, _ := MissingXMLTags(data, test{}); len(mems) != 0 {
+		t.Fatal("optional:", mems)
+	}
+
+	SetRequiredMembers("id", "memo")
+	defer SetRequiredMembers()
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "id" {
+		t.Fatal("required:", mems)
+	}
+	if !omitemptyRequired["memo"] {
+		t.Fatal("not the SetOmitemptyRequired list")
+	}
+}
+
+func TestSetOmitemptyRequired(t *testing.T) {
+	type sub struct {
+		Note string `xml:"note,omitempty"`
+	}
+	type test struct {
+		ID   string `xml:"id,omitempty"`
+		Memo string `xml:"memo,omitempty"`
+		Sub  sub    `xml:"sub"`
+	}
+	data := []byte(`<doc><sub></sub></doc>`)
+
+	SetOmitemptyRequired("id", "sub.note")
+	defer SetOmitemptyRequired()
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameTags(mems, []string{"id", "sub.note"}) {
+		t.Fatal("mems:", mems)
+	}
+
+	SetOmitemptyRequired()
+	mems, _, err = MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("mems:", mems)
+	}
+}
+
+func TestMissingXMLTagsType(t *testing.T) {
+	type test struct {
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	data := []byte(`<doc><ok>true</ok></doc>`)
+
+	mems, root, err := MissingXMLTagsType(data, reflect.TypeOf(test{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(mems) != 1 || mems[0] != "why" {
+		t.Fatal("mems:", root, mems)
+	}
+
+	// a pointer type works, too
+	mems, _, err = MissingXMLTagsType(data, reflect.TypeOf(&test{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "why" {
+		t.Fatal("mems:", mems)
+	}
+}
+
+func TestPointerToSlice(t *testing.T) {
+	type item struct {
+		Name  string `xml:"name"`
+		Price string `xml:"price"`
+	}
+	type test struct {
+		Ok    bool     `xml:"ok"`
+		Items *[]item  `xml:"item"`
+		More  *[]*item `xml:"more"`
+	}
+	data := []byte(`<doc><ok>true</ok><item><name>a</name><price>1</price></item><item><name>b</name><extra/></item><more><name>c</name></more></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 2 || mems[0] != "item.price" || mems[1] != "more.price" {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "item.extra" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// a nil *[]item with no data is just missing
+	mems, _, err = MissingXMLTags([]byte(`<doc><ok>true</ok></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 2 || mems[0] != "item" || mems[1] != "more" {
+		t.Fatal("missing:", mems)
+	}
+}
+
+func TestEmptyRequiredXMLTags(t *testing.T) {
+	type test struct {
+		ID   string `xml:"id,attr"`
+		Ok   bool   `xml:"ok"`
+		Why  string `xml:"why"`
+		Note string `xml:"note,omitempty"`
+		Gone string `xml:"gone"`
+	}
+	data := []byte(`<doc id=""><ok>true</ok><why>  </why><note/></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "gone" {
+		t.Fatal("missing:", mems)
+	}
+	empty, root, err := EmptyRequiredXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(empty) != 2 || empty[0] != "-id" || empty[1] != "why" {
+		t.Fatal("empty:", root, empty)
+	}
+
+	SetOmitemptyRequired("note")
+	defer SetOmitemptyRequired()
+	empty, _, _ = EmptyRequiredXMLTags(data, test{})
+	if len(empty) != 3 || empty[2] != "note" {
+		t.Fatal("empty:", empty)
+	}
+
+	TreatEmptyAsMissing(true)
+	defer TreatEmptyAsMissing(false)
+	empty, _, _ = EmptyRequiredXMLTags(data, test{})
+	if len(empty) != 0 {
+		t.Fatal("empty:", empty)
+	}
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 4 {
+		t.Fatal("missing:", mems)
+	}
+}
+
+func TestSetCheckRoot(t *testing.T) {
+	type invoice struct {
+		XMLName xml.Name `xml:"urn:example Invoice"`
+		ID      string   `xml:"id"`
+	}
+	type plain struct {
+		ID string `xml:"id"`
+	}
+	data := []byte(`<Order><id>1</id></Order>`)
+
+	mems, _, err := MissingXMLTags(data, invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("default:", mems)
+	}
+
+	SetCheckRoot(true)
+	defer SetCheckRoot(false)
+
+	mems, root, err := MissingXMLTags(data, &invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "Order" || len(mems) != 1 || mems[0] != "XMLName(expected=Invoice,got=Order)" {
+		t.Fatal("mismatch:", root, mems)
+	}
+	mems, _, _ = MissingXMLTags([]byte(`<Invoice xmlns="urn:example"><id>1</id></Invoice>`), invoice{})
+	if len(mems) != 0 {
+		t.Fatal("match:", mems)
+	}
+	mems, _, _ = MissingXMLTags(data, plain{})
+	if len(mems) != 0 {
+		t.Fatal("no XMLName:", mems)
+	}
+}
+
+func TestSetMembersToIgnoreGlob(t *testing.T) {
+	type user struct {
+		Name     string `xml:"name"`
+		Password string `xml:"password"`
+	}
+	type test struct {
+		Password string `xml:"password"`
+		User     user   `xml:"user"`
+	}
+	data := []byte(`<doc><user><name>me</name><secret/></user><secret/></doc>`)
+
+	// exact depth
+	SetMembersToIgnore("password")
+	defer SetMembersToIgnore()
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "user.password" {
+		t.Fatal("exact:", mems)
+	}
+
+	// any depth
+	SetMembersToIgnore("*.password")
+	mems, _, err = MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("glob:", mems)
+	}
+
+	SetTagsToIgnore("secret")
+	defer SetTagsToIgnore()
+	tags, _, _ := UnknownXMLTags(data, test{})
+	if len(tags) != 1 || tags[0] != "user.secret" {
+		t.Fatal("exact tags:", tags)
+	}
+	SetTagsToIgnore("*.secret")
+	tags, _, _ = UnknownXMLTags(data, test{})
+	if len(tags) != 0 {
+		t.Fatal("glob tags:", tags)
+	}
+}
+
+func TestSingletonSlice(t *testing.T) {
+	type item struct {
+		Name  string `xml:"name"`
+		Price string `xml:"price"`
+	}
+	type test struct {
+		Items []item   `xml:"item"`
+		Ptrs  []*item  `xml:"ptr"`
+		Tags  []string `xml:"tag"`
+	}
+
+	// one occurrence of each list is decoded by mxj as a single value
+	data := []byte(`<doc><item><name>a</name><price>1</price></item><ptr><name>b</name><price>2</price></ptr><tag>x</tag></doc>`)
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+
+	// the members of a single occurrence are still checked
+	data = []byte(`<doc><item><name>a</name></item><ptr/><tag/></doc>`)
+	mems, _, err = MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 3 || mems[0] != "item.price" || mems[1] != "ptr.name" || mems[2] != "ptr.price" {
+		t.Fatal("missing:", mems)
+	}
+	// an empty element, <ptr/>, is decoded as a zero value
+	tags, _, err = UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+}
+
+func TestAttrOmitempty(t *testing.T) {
+	type sub struct {
+		ID   string `xml:"id,attr,omitempty"`
+		Lang string `xml:",omitempty,attr"`
+		Name string `xml:"name"`
+	}
+	type test struct {
+		ID  string `xml:"id,attr,omitempty"`
+		Sub sub    `xml:"sub"`
+	}
+	data := []byte(`<doc><sub><name>x</name></sub></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("omitempty:", mems)
+	}
+
+	IgnoreOmitemptyTag(false)
+	defer IgnoreOmitemptyTag(true)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 3 || mems[0] != "-id" || mems[1] != "sub.-id" || mems[2] != "sub.-Lang" {
+		t.Fatal("no omitempty:", mems)
+	}
+	mems, _, _ = MissingXMLTagsStd(data, test{})
+	if len(mems) != 3 {
+		t.Fatal("no omitempty std:", mems)
+	}
+
+	// attribute members are ignored at their depth with the prefix
+	SetMembersToIgnore("sub.-id", "-Lang")
+	defer SetMembersToIgnore()
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 2 || mems[0] != "-id" || mems[1] != "sub.-Lang" {
+		t.Fatal("ignore:", mems)
+	}
+	SetMembersToIgnore()
+
+	SetAttrPrefix("@")
+	defer SetAttrPrefix("-")
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 3 || mems[0] != "@id" || mems[1] != "sub.@id" {
+		t.Fatal("prefix:", mems)
+	}
+
+	IgnoreOmitemptyTag(true)
+	SetOmitemptyRequired("sub.@id")
+	defer SetOmitemptyRequired()
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 1 || mems[0] != "sub.@id" {
+		t.Fatal("required:", mems)
+	}
+}
+
+func TestSetMembersToIgnoreAttr(t *testing.T) {
+	type sub struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	type test struct {
+		ID  string `xml:"id,attr"`
+		Sub sub    `xml:"sub"`
+	}
+	data := []byte(`<doc><sub><name>x</name></sub></doc>`)
+
+	SetMembersToIgnore("sub.id@attr")
+	defer SetMembersToIgnore()
+	mems, _, _ := MissingXMLTags(data, test{})
+	if len(mems) != 1 || mems[0] != "-id" {
+		t.Fatal("ignore:", mems)
+	}
+	if bad := ValidateIgnoreList(test{}); len(bad) != 0 {
+		t.Fatal("validate:", bad)
+	}
+
+	v := NewValidator(reflect.TypeOf(test{}), WithMembersToIgnore("id@attr"))
+	if mems, _, _ = v.Missing(data); len(mems) != 1 || mems[0] != "sub.-id" {
+		t.Fatal("validator:", mems)
+	}
+
+	// the suffix doesn't depend on the attribute prefix
+	SetMembersToIgnore("*.id@attr")
+	SetAttrPrefix("@")
+	defer SetAttrPrefix("-")
+	if mems, _, _ = MissingXMLTags(data, test{}); len(mems) != 0 {
+		t.Fatal("glob:", mems)
+	}
+}
+
+func TestNilScalarPointers(t *testing.T) {
+	type test struct {
+		Name  *string `xml:"name"`
+		Count *int    `xml:"count"`
+		Opt   *int    `xml:"opt,omitempty"`
+		Here  *int    `xml:"here"`
+	}
+	data := []byte(`<doc><here>1</here></doc>`)
+
+	want := []string{"name", "count"}
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("nil:", mems)
+	}
+	// a set pointer doesn't make the element present
+	n := 1
+	if mems, _, _ = MissingXMLTags(data, &test{Count: &n}); !reflect.DeepEqual(mems, want) {
+		t.Fatal("set:", mems)
+	}
+	if mems, _, _ = MissingXMLTagsStd(data, test{}); !reflect.DeepEqual(mems, want) {
+		t.Fatal("std:", mems)
+	}
+	details, _, _ := MissingXMLTagsDetailed(data, test{})
+	if len(details) != 2 || details[1].Path != "count" || details[1].Type != "*int" {
+		t.Fatal("detailed:", details)
+	}
+}
+
+func TestCDATA(t *testing.T) {
+	type note struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+	type test struct {
+		Script string `xml:"script"`
+		Empty  string `xml:"empty"`
+		Note   note   `xml:"note"`
+	}
+	data := []byte(`<doc><script><![CDATA[ if (a < b) { x(); } ]]></script><empty><![CDATA[]]></empty><note lang="en"><![CDATA[<hi>]]></note></doc>`)
+
+	TreatEmptyAsMissing(true)
+	defer TreatEmptyAsMissing(false)
+
+	for _, f := range []func([]byte, interface{}) ([]string, string, error){MissingXMLTags, MissingXMLTagsStd} {
+		mems, _, err := f(data, test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mems) != 1 || mems[0] != "empty" {
+			t.Fatal("missing:", mems)
+		}
+	}
+	for _, f := range []func([]byte, interface{}) ([]string, string, error){UnknownXMLTags, UnknownXMLTagsStd} {
+		tags, _, err := f(data, test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != 0 {
+			t.Fatal("unknown:", tags)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	// the README/doc.go example
+	data := []byte(`<doc>
+	           <elem1>a simple element</elem1>
+	           <elem2>
+	             <subelem>something more complex</subelem>
+	             <notes>take a look at this</notes>
+	           </elem2>
+	           <elem4>extraneous</elem4>
+	         </doc>`)
+
+	type sub struct {
+		Subelem string `xml:"subelem,omitempty"`
+		Another string `xml:"another"`
+	}
+	type elem struct {
+		Elem1 string `xml:"elem1"`
+		Elem2 sub    `xml:"elem2"`
+		Elem3 bool   `xml:"elem3"`
+	}
+
+	// elem1, elem2, elem2.another, elem3 - subelem is optional
+	defined, matched, root, err := Coverage(data, elem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || defined != 4 || matched != 2 {
+		t.Fatal("coverage:", root, defined, matched)
+	}
+
+	IgnoreOmitemptyTag(false)
+	defer IgnoreOmitemptyTag(true)
+	defined, matched, _, _ = Coverage(data, elem{})
+	if defined != 5 || matched != 3 {
+		t.Fatal("no omitempty:", defined, matched)
+	}
+
+	SetMembersToIgnore("elem3")
+	defer SetMembersToIgnore()
+	defined, matched, _, _ = Coverage(data, elem{})
+	if defined != 4 || matched != 3 {
+		t.Fatal("ignore:", defined, matched)
+	}
+}
+
+func TestPresentXMLTags(t *testing.T) {
+	data := []byte(`<doc>
+	           <elem1>a simple element</elem1>
+	           <elem2>
+	             <subelem>something more complex</subelem>
+	             <notes>take a look at this</notes>
+	           </elem2>
+	           <item><id>1</id></item>
+	           <item><id>2</id><name>two</name></item>
+	         </doc>`)
+
+	type sub struct {
+		Subelem string `xml:"subelem,omitempty"`
+		Another string `xml:"another"`
+	}
+	type item struct {
+		ID   string `xml:"id"`
+		Name string `xml:"name"`
+	}
+	type elem struct {
+		Elem1 string `xml:"elem1"`
+		Elem2 sub    `xml:"elem2"`
+		Elem3 bool   `xml:"elem3"`
+		Items []item `xml:"item"`
+	}
+
+	tags, root, err := PresentXMLTags(data, elem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"elem1", "elem2", "elem2.subelem", "item", "item.id", "item.name"}
+	if root != "doc" || !reflect.DeepEqual(tags, want) {
+		t.Fatal("present:", root, tags)
+	}
+
+	SetMembersToIgnore("elem2")
+	defer SetMembersToIgnore()
+	tags, _, _ = PresentXMLTags(data, elem{})
+	want = []string{"elem1", "item", "item.id", "item.name"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("ignore:", tags)
+	}
+
+	if _, _, err = PresentXMLTags([]byte(`<doc>`), elem{}); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestSetEmptyFunc(t *testing.T) {
+	type test struct {
+		Name  string `xml:"name"`
+		Phone string `xml:"phone"`
+		Fax   string `xml:"fax"`
+	}
+	data := []byte(`<doc><name>me</name><phone>N/A</phone><fax/></doc>`)
+
+	TreatEmptyAsMissing(true)
+	defer TreatEmptyAsMissing(false)
+
+	mems, _, _ := MissingXMLTags(data, test{})
+	if len(mems) != 1 || mems[0] != "fax" {
+		t.Fatal("default:", mems)
+	}
+
+	SetEmptyFunc(func(v interface{}) bool {
+		s, ok := v.(string)
+		return ok && (s == "" || s == "N/A")
+	})
+	defer SetEmptyFunc(nil)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 2 || mems[0] != "phone" || mems[1] != "fax" {
+		t.Fatal("N/A:", mems)
+	}
+
+	TreatEmptyAsMissing(false)
+	empty, _, _ := EmptyRequiredXMLTags(data, test{})
+	if len(empty) != 2 {
+		t.Fatal("empty:", empty)
+	}
+
+	SetEmptyFunc(nil)
+	empty, _, _ = EmptyRequiredXMLTags(data, test{})
+	if len(empty) != 1 || empty[0] != "fax" {
+		t.Fatal("reset:", empty)
+	}
+}
+
+func TestSetCheckRootNamespace(t *testing.T) {
+	type invoice struct {
+		XMLName xml.Name `xml:"urn:example:invoice Invoice"`
+		ID      string   `xml:"urn:example:invoice id"`
+		Note    string   `xml:"urn:example:invoice note,attr"`
+	}
+	SetCheckRoot(true)
+	defer SetCheckRoot(false)
+
+	for _, data := range []string{
+		`<inv:Invoice xmlns:inv="urn:example:invoice" inv:note="x"><inv:id>1</inv:id></inv:Invoice>`,
+		`<Invoice xmlns="urn:example:invoice" note="x"><id>1</id></Invoice>`,
+	} {
+		mems, _, err := MissingXMLTags([]byte(data), invoice{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mems) != 0 {
+			t.Fatal("match:", data, mems)
+		}
+		mems, _, _ = MissingXMLTagsStd([]byte(data), invoice{})
+		if len(mems) != 0 {
+			t.Fatal("match std:", data, mems)
+		}
+	}
+
+	data := []byte(`<o:Invoice xmlns:o="urn:other" note="x"><id>1</id></o:Invoice>`)
+	mems, _, err := MissingXMLTags(data, invoice{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 1 || mems[0] != "XMLName(expected=urn:example:invoice Invoice,got=urn:other Invoice)" {
+		t.Fatal("mismatch:", mems)
+	}
+	// only the local name is available for MissingXMLTagsReader
+	mems, _, _ = MissingXMLTagsReader(bytes.NewReader(data), invoice{})
+	if len(mems) != 0 {
+		t.Fatal("reader:", mems)
+	}
+}
+
+func TestInterfaceMember(t *testing.T) {
+	type test struct {
+		ID   string      `xml:"id"`
+		Any  interface{} `xml:"any"`
+		More interface{} `xml:"more"`
+	}
+	data := []byte(`<doc><id>1</id><any><a>1</a><b><c x="y">2</c></b></any></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, &test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+	diff, _, err := Cross(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != 0 {
+		t.Fatal("cross:", diff)
+	}
+}
+
+func TestRootAttrMembers(t *testing.T) {
+	type test struct {
+		Version string `xml:"version,attr"`
+		Ok      bool   `xml:"ok"`
+	}
+	data := []byte(`<doc version="2" extra="x"><ok>true</ok></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "-extra" {
+		t.Fatal("unknown:", tags)
+	}
+
+	data = []byte(`<doc extra="x"><ok>true</ok></doc>`)
+	mems, _, _ = MissingXMLTagsReader(bytes.NewReader(data), test{})
+	if len(mems) != 1 || mems[0] != "-version" {
+		t.Fatal("reader missing:", mems)
+	}
+	mems, _, _ = MissingXMLTagsStd(data, test{})
+	if len(mems) != 1 || mems[0] != "-version" {
+		t.Fatal("std missing:", mems)
+	}
+	tags, _, _ = UnknownXMLTagsStd(data, test{})
+	if len(tags) != 1 || tags[0] != "-extra" {
+		t.Fatal("std unknown:", tags)
+	}
+}
+
+func TestSliceVal(t *testing.T) {
+	type item struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	data := []byte(`<items version="1">
+	  <item id="1"><name>a</name></item>
+	  <item id="2"><name>b</name><color>red</color></item>
+	  <item><name>c</name></item>
+	</items>`)
+
+	mems, root, err := MissingXMLTags(data, []item{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "items" || len(mems) != 1 || mems[0] != "item.-id" {
+		t.Fatal("missing:", root, mems)
+	}
+	tags, _, err := UnknownXMLTags(data, &[]*item{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(tags)
+	if len(tags) != 2 || tags[0] != "-version" || tags[1] != "item.color" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// a single child element
+	data = []byte(`<items><item id="1"/></items>`)
+	mems, _, _ = MissingXMLTags(data, []item{})
+	if len(mems) != 1 || mems[0] != "item.name" {
+		t.Fatal("single:", mems)
+	}
+}
+
+func TestSetMaxReadSize(t *testing.T) {
+	type test struct {
+		Ok []bool `xml:"ok"`
+	}
+	data := []byte("<doc>" + strings.Repeat("<ok>true</ok>", 100) + "</doc>")
+
+	SetMaxReadSize(int64(len(data)) - 1)
+	defer SetMaxReadSize(0)
+	if _, _, err := MissingXMLTagsReader(bytes.NewReader(data), test{}); err != ErrTooLarge {
+		t.Fatal("reader:", err)
+	}
+	if _, _, err := UnknownXMLTagsReaderContext(context.Background(), bytes.NewReader(data), test{}); err != ErrTooLarge {
+		t.Fatal("unknown:", err)
+	}
+	_, _, _, raw, err := MissingXMLTagsReaderMapRaw(bytes.NewReader(data), test{})
+	if err != ErrTooLarge || len(raw) > len(data) {
+		t.Fatal("raw:", err, len(raw))
+	}
+
+	// the endless document
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	SetMaxReadSize(64)
+	if _, _, err := MissingXMLTagsReaderContext(ctx, &slowReader{}, test{}); err != ErrTooLarge {
+		t.Fatal("endless:", err)
+	}
+
+	// a document of exactly the limit, with or without data after it
+	SetMaxReadSize(int64(len(data)))
+	mems, _, err := MissingXMLTagsReader(bytes.NewReader(data), test{})
+	if err != nil || len(mems) != 0 {
+		t.Fatal("limit:", mems, err)
+	}
+	for _, trail := range []string{"\n", "<doc/>", "not XML"} {
+		r := bytes.NewReader(append(append([]byte{}, data...), trail...))
+		if mems, _, err = MissingXMLTagsReader(r, test{}); err != nil || len(mems) != 0 {
+			t.Fatalf("limit %q: %v %v", trail, mems, err)
+		}
+	}
+	SetMaxReadSize(int64(len(data)) - 1)
+	r := bytes.NewReader(append(append([]byte{}, data...), '\n'))
+	if _, _, err = MissingXMLTagsReader(r, test{}); err != ErrTooLarge {
+		t.Fatal("limit-1:", err)
+	}
+
+	// the limit for a call
+	if _, err = CheckReader(bytes.NewReader(data), test{}, WithMaxReadSize(64)); err != ErrTooLarge {
+		t.Fatal("option:", err)
+	}
+	SetMaxReadSize(0)
+	if _, err = CheckReader(bytes.NewReader(data), test{}, WithMaxReadSize(int64(len(data)))); err != nil {
+		t.Fatal("option limit:", err)
+	}
+}
+
+func TestMissingScalarSlice(t *testing.T) {
+	type test struct {
+		ID   string   `xml:"id"`
+		Tags []string `xml:"tag"`
+		Opt  []string `xml:"opt,omitempty"`
+		Nums *[]int   `xml:"num"`
+	}
+	mems, _, err := MissingXMLTags([]byte(`<doc><id>1</id></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mems, []string{"tag", "num"}) {
+		t.Fatal("absent:", mems)
+	}
+
+	mems, _, _ = MissingXMLTags([]byte(`<doc><id>1</id><tag>a</tag><tag>b</tag><num>1</num></doc>`), test{})
+	if len(mems) != 0 {
+		t.Fatal("present:", mems)
+	}
+
+	IgnoreOmitemptyTag(false)
+	defer IgnoreOmitemptyTag(true)
+	mems, _, _ = MissingXMLTags([]byte(`<doc><id>1</id><tag>a</tag><num>1</num></doc>`), test{})
+	if len(mems) != 1 || mems[0] != "opt" {
+		t.Fatal("omitempty:", mems)
+	}
+}
+
+func TestOmitemptyListMembers(t *testing.T) {
+	type item struct {
+		A string `xml:"a"`
+	}
+	type test struct {
+		Name  string  `xml:"name"`
+		Items []item  `xml:"item,omitempty"`
+		Ptr   *item   `xml:"ptr,omitempty"`
+		List  *[]item `xml:"list,omitempty"`
+	}
+	data := []byte(`<doc><name>x</name></doc>`)
+
+	// an absent optional list has no elements, so neither it nor the members
+	// of its elements are missing - whatever the struct value holds
+	for _, val := range []interface{}{test{}, &test{Items: []item{{}}, Ptr: &item{}}} {
+		mems, _, err := MissingXMLTags(data, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mems) != 0 {
+			t.Fatal("omitempty:", mems)
+		}
+	}
+
+	IgnoreOmitemptyTag(false)
+	defer IgnoreOmitemptyTag(true)
+	mems, _, _ := MissingXMLTags(data, test{})
+	want := []string{"item", "item.a", "ptr", "list"}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("no omitempty:", mems)
+	}
+	IgnoreOmitemptyTag(true)
+
+	// a present list's elements are checked
+	mems, _, _ = MissingXMLTags([]byte(`<doc><name>x</name><item/><item><a>1</a></item></doc>`), test{})
+	if len(mems) != 1 || mems[0] != "item.a" {
+		t.Fatal("present:", mems)
+	}
+}
+
+func TestStrictRootKind(t *testing.T) {
+	type test struct {
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	data := []byte(`<doc>just text</doc>`)
+
+	// by default the type name is reported
+	mems, root, err := MissingXMLTags(data, test{})
+	if err != nil || !reflect.DeepEqual(mems, []string{"test"}) || root != "doc" {
+		t.Fatal("lenient:", mems, root, err)
+	}
+
+	StrictRootKind(true)
+	defer StrictRootKind(false)
+	mems, _, err = MissingXMLTags(data, test{})
+	if err != ErrRootKind || len(mems) != 0 {
+		t.Fatal("strict:", mems, err)
+	}
+	if mems, _, err = MissingXMLTagsStd(data, test{}); err != ErrRootKind || len(mems) != 0 {
+		t.Fatal("strict std:", mems, err)
+	}
+	if _, err = Check(data, test{}); err != ErrRootKind {
+		t.Fatal("strict check:", err)
+	}
+	// a document with subelements isn't affected
+	if mems, _, err = MissingXMLTags([]byte(`<doc><ok>true</ok></doc>`), test{}); err != nil || len(mems) != 1 {
+		t.Fatal("strict, subelements:", mems, err)
+	}
+	StrictRootKind(false)
+
+	v := NewValidator(reflect.TypeOf(test{}), WithStrictRootKind(true))
+	if _, _, err = v.Missing(data); err != ErrRootKind {
+		t.Fatal("validator:", err)
+	}
+	if mems, _, err = MissingXMLTags(data, test{}); err != nil || len(mems) != 1 {
+		t.Fatal("package settings:", mems, err)
+	}
+}
+
+func TestMissingXMLTagsBoth(t *testing.T) {
+	type inner struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+	type sub struct {
+		Subelem string  `xml:"subelem"`
+		Another []inner `xml:"another"`
+		Plain   string
+	}
+	type elem struct {
+		Elem1 string `xml:"elem1"`
+		Elem2 *sub   `xml:"elem2"`
+	}
+	data := []byte(`<doc><elem2><subelem>x</subelem><another>hi</another></elem2></doc>`)
+
+	pairs, root, err := MissingXMLTagsBoth(data, elem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TagPair{
+		{"elem1", "Elem1"},
+		{"elem2.another.-lang", "Elem2.Another.Lang"},
+		{"elem2.Plain", "Elem2.Plain"},
+	}
+	if root != "doc" || !reflect.DeepEqual(pairs, want) {
+		t.Fatal("pairs:", root, pairs)
+	}
+
+	// the child elements of a top-level list aren't members
+	pairs, _, _ = MissingXMLTagsBoth([]byte(`<list><elem><elem1>a</elem1></elem></list>`), []elem{})
+	if len(pairs) != 1 || pairs[0] != (TagPair{"elem.elem2", "elem.Elem2"}) {
+		t.Fatal("list:", pairs)
+	}
+}
+
+func TestSuppressChildrenOfMissingOptional(t *testing.T) {
+	type addr struct {
+		Street string `xml:"street"`
+		City   string `xml:"city"`
+	}
+	type test struct {
+		Name    string `xml:"name"`
+		Home    addr   `xml:"home,omitempty"`
+		Work    *addr  `xml:"work"`
+		Billing addr   `xml:"billing,omitempty"`
+	}
+	data := []byte(`<doc><name>x</name><billing><street>y</street></billing></doc>`)
+
+	mems, _, _ := MissingXMLTags(data, test{})
+	want := []string{"home.street", "home.city", "work", "billing.city"}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("default:", mems)
+	}
+
+	SuppressChildrenOfMissingOptional(true)
+	defer SuppressChildrenOfMissingOptional(false)
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"work", "billing.city"}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("suppressed:", mems)
+	}
+
+	// a required "omitempty" member isn't optional
+	SetOmitemptyRequired("home")
+	defer SetOmitemptyRequired()
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 5 || mems[0] != "home" {
+		t.Fatal("required:", mems)
+	}
+}
+
+func TestDetailedDepth(t *testing.T) {
+	type leaf struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	type mid struct {
+		Leaf leaf   `xml:"leaf"`
+		Note string `xml:"note"`
+	}
+	type test struct {
+		Mid   mid    `xml:"mid"`
+		Title string `xml:"title"`
+	}
+	data := []byte(`<doc><mid><leaf><extra>x</extra></leaf><more>y</more></mid><other>z</other></doc>`)
+
+	mt, _, err := MissingXMLTagsDetailed(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdepth := map[string]int{}
+	for _, v := range mt {
+		mdepth[v.Path] = v.Depth
+	}
+	want := map[string]int{"mid.leaf.-id": 3, "mid.leaf.name": 3, "mid.note": 2, "title": 1}
+	if !reflect.DeepEqual(mdepth, want) {
+		t.Fatal("missing:", mt)
+	}
+
+	ut, _, err := UnknownXMLTagsDetailed(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	udepth := map[string]int{}
+	for _, v := range ut {
+		udepth[v.Path] = v.Depth
+	}
+	want = map[string]int{"mid.leaf.extra": 3, "mid.more": 2, "other": 1}
+	if !reflect.DeepEqual(udepth, want) {
+		t.Fatal("unknown:", ut)
+	}
+
+	if TagDepth("") != 0 {
+		t.Fatal("empty tag depth:", TagDepth(""))
+	}
+}
+
+type category struct {
+	Name string     `xml:"name"`
+	Sub  []category `xml:"sub,omitempty"`
+}
+
+func TestRecursiveSlice(t *testing.T) {
+	data := []byte(`<doc>
+		<name>root</name>
+		<sub><name>a</name><sub><code>1</code></sub></sub>
+		<sub><name>b</name></sub>
+	</doc>`)
+
+	mems, _, err := MissingXMLTags(data, category{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the absent <sub> lists are optional, so they have no members to report
+	want := []string{"sub.sub.name"}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("missing:", mems)
+	}
+
+	SuppressChildrenOfMissingOptional(true)
+	defer SuppressChildrenOfMissingOptional(false)
+	mems, _, _ = MissingXMLTags(data, category{})
+	if !reflect.DeepEqual(mems, []string{"sub.sub.name"}) {
+		t.Fatal("suppressed:", mems)
+	}
+
+	tags, _, err := UnknownXMLTags(data, category{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "sub.sub.code" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// a required recursive member
+	type node struct {
+		ID   string  `xml:"id,attr"`
+		Kids []node  `xml:"kid"`
+		Next *[]node `xml:"next"`
+	}
+	mems, _, _ = MissingXMLTags([]byte(`<doc id="1"><kid id="2"/></doc>`), node{})
+	want = []string{"kid.kid", "kid.kid.-id", "kid.kid.kid", "kid.kid.next", "kid.next", "next"}
+	if !reflect.DeepEqual(mems, want) {
+		t.Fatal("required:", mems)
+	}
+}
+
+type concItem struct {
+	A string `xml:"a"`
+	B string `xml:"b"`
+}
+
+type concBase struct {
+	ID     string `xml:"id,attr"`
+	Secret string `xml:"secret"`
+}
+
+type concDoc struct {
+	concBase
+	Item  concItem   `xml:"item"`
+	Cats  []category `xml:"cat"`
+	Extra string     `xml:"extra"`
+}
+
+// The type specs, concDoc isn't used elsewhere, are parsed and cached by the
+// concurrent calls, and the results mustn't depend on map order.
+func TestConcurrentMissingXMLTags(t *testing.T) {
+	RegisterIgnore(reflect.TypeOf(concDoc{}), nil, []string{"secret"})
+	defer RegisterIgnore(reflect.TypeOf(concDoc{}), nil, nil)
+	SetCaseInsensitive(true)
+	defer SetCaseInsensitive(false)
+	data := []byte(`<doc>
+		<Item><a>1</a></Item><ITEM><b>2</b></ITEM>
+		<cat><name>x</name><sub><code>1</code></sub></cat>
+		<cat><sub><name>y</name></sub></cat>
+	</doc>`)
+
+	const n = 100
+	var wg sync.WaitGroup
+	results := make([][]string, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, _ = MissingXMLTags(data, concDoc{})
+		}(i)
+	}
+	wg.Wait()
+	// <ITEM> is checked, since it's the first of the matching keys in sort order
+	want := []string{"-id", "item.a", "cat.sub.name", "cat.name", "extra"}
+	for i, r := range results {
+		if !reflect.DeepEqual(r, want) {
+			t.Fatalf("call %d: %v", i, r)
+		}
+	}
+}
+
+func TestMissingStructMembers(t *testing.T) {
+	type sub struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	type test struct {
+		Sub sub  `xml:"sub"`
+		Ok  bool `xml:"ok"`
+	}
+
+	// the element is absent
+	mems, _, err := MissingXMLTags([]byte(`<doc><ok>true</ok></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mems, []string{"sub", "sub.-id", "sub.name"}) {
+		t.Fatal("absent:", mems)
+	}
+
+	// the element is a simple element, so none of its members are set
+	mems, _, err = MissingXMLTags([]byte(`<doc><ok>true</ok><sub>text</sub></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mems, []string{"sub.-id", "sub.name"}) {
+		t.Fatal("simple:", mems)
+	}
+}
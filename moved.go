@@ -0,0 +1,46 @@
+// moved.go - correlate missing and unknown tags for relocated elements
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"sort"
+	"strings"
+)
+
+// MovedXMLTags correlates the tags reported by MissingXMLTags with those reported
+// by UnknownXMLTags that have the same name - the last element of the dot-notation
+// tag - and returns the pairs as "missing -> unknown"; e.g., if <note> was moved
+// from <header> to <body> in the XML data, "header.note -> body.note" is returned.
+// Each missing tag is paired with at most one unknown tag, and vice versa; if more
+// than one unknown tag has the same name, the first in sort order is used.  The
+// pairs are in the order of the missing tags.  If a check is terminated with an
+// error, e.g., ErrTruncated, the pairs of the tags found up to that point are
+// returned with it.
+func MovedXMLTags(b []byte, val interface{}) ([]string, string, error) {
+	root, _, missing, unknown, err := checkXMLTags(b, val, nil)
+	if missing == nil {
+		return nil, root, err
+	}
+
+	utags := make([]string, len(unknown.tags))
+	copy(utags, unknown.tags)
+	sort.Strings(utags)
+	used := make([]bool, len(utags))
+
+	var moved []string
+	for _, m := range missing.tags {
+		name := m[strings.LastIndex(m, ".")+1:]
+		for i, u := range utags {
+			if used[i] || u[strings.LastIndex(u, ".")+1:] != name {
+				continue
+			}
+			used[i] = true
+			moved = append(moved, m+" -> "+u)
+			break
+		}
+	}
+	return moved, root, err
+}
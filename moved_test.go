@@ -0,0 +1,46 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMovedXMLTags(t *testing.T) {
+	type header struct {
+		ID   string `xml:"id"`
+		Note string `xml:"note"`
+	}
+	type body struct {
+		Text string `xml:"text"`
+	}
+	type test struct {
+		Header header `xml:"header"`
+		Body   body   `xml:"body"`
+		Date   string `xml:"date"`
+	}
+
+	// <note> was moved from <header> to <body>; <date> and <extra> aren't related
+	data := []byte(`<doc>
+	  <header><id>1</id></header>
+	  <body><text>hello</text><note>moved</note><extra/></body>
+	</doc>`)
+	moved, root, err := MovedXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || !reflect.DeepEqual(moved, []string{"header.note -> body.note"}) {
+		t.Fatal("moved:", root, moved)
+	}
+
+	// each unknown tag is paired once
+	data = []byte(`<doc><body><text/><id>1</id><note>x</note></body><note>y</note></doc>`)
+	moved, _, _ = MovedXMLTags(data, test{})
+	want := []string{"header.id -> body.id", "header.note -> body.note"}
+	if !reflect.DeepEqual(moved, want) {
+		t.Fatal("pairs:", moved)
+	}
+
+	if _, _, err = MovedXMLTags([]byte(`<doc>`), test{}); err == nil {
+		t.Fatal("no error")
+	}
+}
@@ -0,0 +1,319 @@
+// options.go - per-Validator settings
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import "strings"
+
+// options holds the settings that control the checks.  The package functions
+// use a snapshot of the package settings - see currentOptions - and a Validator
+// has its own.
+type options struct {
+	skiptags          []string
+	skipmembers       []skipmems
+	skipsubtrees      []string
+	deprecated        []string
+	omitemptyOK       bool
+	omitemptyRequired map[string]bool
+	suppressOptional  bool
+	unexportedKnown   bool
+	emptyAsMissing    bool
+	emptyFunc         func(interface{}) bool
+	nameNormalizer    func(string) string
+	caseInsensitive   bool
+	keyMapper         func(string) string
+	maxDepth          int
+	maxResults        int
+	maxReadSize       int64
+	suggestThreshold  int
+	attrPrefix        string
+	ignoreAttrs       bool
+	attrOrElement     bool
+	checkRoot         bool
+	strictRootKind    bool
+	docOrder          bool
+	mxjCast           bool
+	keepMap           bool // see WithMap
+	firstFinding      bool // stop at the first missing or unknown tag; see IsValid
+}
+
+// currentOptions returns a snapshot of the package settings.  The package setter
+// functions replace, rather than modify, the lists and maps, so they can be shared.
+func currentOptions() *options {
+	return &options{
+		skiptags:          skiptags,
+		skipmembers:       skipmembers,
+		skipsubtrees:      skipsubtrees,
+		deprecated:        deprecatedTags,
+		omitemptyOK:       omitemptyOK,
+		omitemptyRequired: omitemptyRequired,
+		suppressOptional:  suppressOptional,
+		unexportedKnown:   unexportedKnown,
+		emptyAsMissing:    emptyAsMissing,
+		emptyFunc:         emptyFunc,
+		nameNormalizer:    nameNormalizer,
+		caseInsensitive:   caseInsensitive,
+		keyMapper:         keyMapper,
+		maxDepth:          maxDepth,
+		maxResults:        maxResults,
+		maxReadSize:       maxReadSize,
+		suggestThreshold:  suggestThreshold,
+		attrPrefix:        attrPrefix,
+		ignoreAttrs:       ignoreAttrs,
+		attrOrElement:     attrOrElement,
+		checkRoot:         checkRootOK,
+		strictRootKind:    strictRootKind,
+		docOrder:          docOrder,
+		mxjCast:           mxjCast,
+	}
+}
+
+// isEmpty reports whether the mxj.Map value 'v' is empty; see SetEmptyFunc.
+func (o *options) isEmpty(v interface{}) bool {
+	if o.emptyFunc != nil {
+		return o.emptyFunc(v)
+	}
+	return isEmptyValue(v)
+}
+
+// looseNames reports whether tags that don't match exactly are compared using
+// normalKey; see SetNameNormalizer, SetCaseInsensitive and SetKeyMapper.
+func (o *options) looseNames() bool {
+	return o.nameNormalizer != nil || o.caseInsensitive || o.keyMapper != nil
+}
+
+// normalKey applies the name normalizer, see SetNameNormalizer, and case folding,
+// see SetCaseInsensitive, to the mxj.Map key 'k', keeping any attribute prefix.
+func (o *options) normalKey(k string) string {
+	var prefix string
+	if isAttrTag(k, o.attrPrefix) {
+		prefix, k = o.attrPrefix, k[len(o.attrPrefix):]
+	}
+	if o.nameNormalizer != nil {
+		k = o.nameNormalizer(k)
+	}
+	if o.caseInsensitive {
+		k = strings.ToLower(k)
+	}
+	return prefix + k
+}
+
+// dataKey is normalKey for the XML data key 'k' - after applying the key mapper,
+// see SetKeyMapper.
+func (o *options) dataKey(k string) string {
+	if o.keyMapper != nil {
+		k = o.keyMapper(k)
+	}
+	return o.normalKey(k)
+}
+
+// otherKind returns the mxj.Map key for the XML data of the other kind, attribute
+// or element, than the member key 'k'; see MatchAttrOrElement.
+func (o *options) otherKind(k string, attr bool) string {
+	if attr {
+		return k[len(o.attrPrefix):]
+	}
+	return o.attrPrefix + k
+}
+
+// Option is a setting for a Validator; see NewValidator.  Each Option corresponds
+// to one of the package setter functions, which maintain the package settings.
+type Option func(*options)
+
+// WithTagsToIgnore is SetTagsToIgnore for a Validator.
+func WithTagsToIgnore(s ...string) Option {
+	tags := make([]string, len(s))
+	copy(tags, s)
+	return func(o *options) {
+		o.skiptags = tags
+	}
+}
+
+// WithMembersToIgnore is SetMembersToIgnore for a Validator.
+func WithMembersToIgnore(s ...string) Option {
+	mems := make([]skipmems, len(s))
+	for i, v := range s {
+		mems[i] = newSkipmems(v)
+	}
+	return func(o *options) {
+		o.skipmembers = mems
+	}
+}
+
+// WithSubtreesToIgnore is SetSubtreesToIgnore for a Validator.
+func WithSubtreesToIgnore(s ...string) Option {
+	subtrees := make([]string, len(s))
+	copy(subtrees, s)
+	return func(o *options) {
+		o.skipsubtrees = subtrees
+	}
+}
+
+// WithDeprecatedTags is SetDeprecatedTags for a Validator.
+func WithDeprecatedTags(s ...string) Option {
+	tags := make([]string, len(s))
+	copy(tags, s)
+	return func(o *options) {
+		o.deprecated = tags
+	}
+}
+
+// WithOmitemptyTag is IgnoreOmitemptyTag(ok) for a Validator.
+func WithOmitemptyTag(ok bool) Option {
+	return func(o *options) {
+		o.omitemptyOK = ok
+	}
+}
+
+// WithOmitemptyRequired is SetOmitemptyRequired for a Validator.
+func WithOmitemptyRequired(s ...string) Option {
+	req := make(map[string]bool, len(s))
+	for _, v := range s {
+		req[v] = true
+	}
+	return func(o *options) {
+		o.omitemptyRequired = req
+	}
+}
+
+// WithSuppressChildrenOfMissingOptional is SuppressChildrenOfMissingOptional(ok)
+// for a Validator.
+func WithSuppressChildrenOfMissingOptional(ok bool) Option {
+	return func(o *options) {
+		o.suppressOptional = ok
+	}
+}
+
+// WithUnexportedAsKnown is ReportUnexportedAsKnown(ok) for a Validator.
+func WithUnexportedAsKnown(ok bool) Option {
+	return func(o *options) {
+		o.unexportedKnown = ok
+	}
+}
+
+// WithEmptyAsMissing is TreatEmptyAsMissing(ok) for a Validator.
+func WithEmptyAsMissing(ok bool) Option {
+	return func(o *options) {
+		o.emptyAsMissing = ok
+	}
+}
+
+// WithEmptyFunc is SetEmptyFunc for a Validator.
+func WithEmptyFunc(fn func(value interface{}) bool) Option {
+	return func(o *options) {
+		o.emptyFunc = fn
+	}
+}
+
+// WithNameNormalizer is SetNameNormalizer for a Validator.
+func WithNameNormalizer(fn func(string) string) Option {
+	return func(o *options) {
+		o.nameNormalizer = fn
+	}
+}
+
+// WithCaseInsensitive is SetCaseInsensitive(ok) for a Validator.
+func WithCaseInsensitive(ok bool) Option {
+	return func(o *options) {
+		o.caseInsensitive = ok
+	}
+}
+
+// WithKeyMapper is SetKeyMapper(fn) for a Validator.
+func WithKeyMapper(fn func(string) string) Option {
+	return func(o *options) {
+		o.keyMapper = fn
+	}
+}
+
+// WithMaxDepth is SetMaxDepth for a Validator.
+func WithMaxDepth(n int) Option {
+	if n < 0 {
+		n = 0
+	}
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxResults is SetMaxResults for a Validator.
+func WithMaxResults(n int) Option {
+	if n < 0 {
+		n = 0
+	}
+	return func(o *options) {
+		o.maxResults = n
+	}
+}
+
+// WithMaxReadSize is SetMaxReadSize for a Validator; it applies to CheckReader.
+func WithMaxReadSize(n int64) Option {
+	if n < 0 {
+		n = 0
+	}
+	return func(o *options) {
+		o.maxReadSize = n
+	}
+}
+
+// WithSuggestThreshold is SetSuggestThreshold for a Validator.
+func WithSuggestThreshold(n int) Option {
+	if n < 0 {
+		n = 0
+	}
+	return func(o *options) {
+		o.suggestThreshold = n
+	}
+}
+
+// WithAttrPrefix is SetAttrPrefix for a Validator.  As with SetAttrPrefix, the
+// prefix must match the mxj package prefix, which is global.
+func WithAttrPrefix(s string) Option {
+	return func(o *options) {
+		o.attrPrefix = s
+	}
+}
+
+// WithIgnoreAllAttrs is IgnoreAllAttrs(ok) for a Validator.
+func WithIgnoreAllAttrs(ok bool) Option {
+	return func(o *options) {
+		o.ignoreAttrs = ok
+	}
+}
+
+// WithMatchAttrOrElement is MatchAttrOrElement(ok) for a Validator.
+func WithMatchAttrOrElement(ok bool) Option {
+	return func(o *options) {
+		o.attrOrElement = ok
+	}
+}
+
+// WithCheckRoot is SetCheckRoot(ok) for a Validator.
+func WithCheckRoot(ok bool) Option {
+	return func(o *options) {
+		o.checkRoot = ok
+	}
+}
+
+// WithStrictRootKind is StrictRootKind(ok) for a Validator.
+func WithStrictRootKind(ok bool) Option {
+	return func(o *options) {
+		o.strictRootKind = ok
+	}
+}
+
+// WithOrderByDocument is OrderByDocument(ok) for a Validator.
+func WithOrderByDocument(ok bool) Option {
+	return func(o *options) {
+		o.docOrder = ok
+	}
+}
+
+// WithMxjCast is SetMxjCast(ok) for a Validator.
+func WithMxjCast(ok bool) Option {
+	return func(o *options) {
+		o.mxjCast = ok
+	}
+}
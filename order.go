@@ -0,0 +1,250 @@
+// order.go - identify XML data elements that are not in struct member order
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// CheckElementOrder returns a slice of the tags for XML data elements that occur
+// out of sequence with respect to the order in which the corresponding members are
+// declared in 'val', which is of type struct.  An element is out of sequence if it
+// follows an element that is decoded to a member declared after its own member.
+// For complex elements the tags are reported using dot-notation and each tag is
+// reported once.  Elements that don't correspond to a struct member, attributes,
+// and any tags listed with SetTagsToIgnore are not considered.
+//
+// The encoding/xml Unmarshal function does not depend on element order, so this is
+// only of interest for order-sensitive consumers of the XML data.
+//
+//	Example:
+//		type doc struct {
+//			A string `xml:"a"`
+//			B string `xml:"b"`
+//			C string `xml:"c"`
+//		}
+//		tags, _ := CheckElementOrder([]byte(`<doc><b/><a/><c/></doc>`), doc{})
+//		fmt.Println(tags) // prints: [a]
+//
+// NOTE: the XML data is decoded using mxj.NewMapXmlSeq, which preserves the
+// sequence of the elements as "#seq" keys, rather than mxj.NewMapXml; so the
+// mxj.Map representation differs from that of the MissingXMLTags and UnknownXMLTags
+// functions and the SetMxjCast setting is not used.
+// (See github.com/clbanning/mxj documentation of mxj.NewMapXmlSeq.)
+func CheckElementOrder(b []byte, val interface{}) ([]string, error) {
+	m, err := mxj.NewMapXmlSeq(skipBOM(b))
+	if err != nil {
+		return nil, newParseError(err, b)
+	}
+	// strip off the root value
+	var v interface{}
+	for _, v = range m {
+		break
+	}
+
+	var s tagList
+	checkOrder(v, reflect.TypeOf(val), &s, "", make(map[string]bool))
+	return s.tags, s.err
+}
+
+// seqElem is a child element of a mxj.NewMapXmlSeq map value.
+type seqElem struct {
+	seq int
+	key string
+	val interface{}
+}
+
+// seqElems returns the child elements of 'mm' in document order.
+func seqElems(mm map[string]interface{}) []seqElem {
+	var elems []seqElem
+	for k, v := range mm {
+		// "#seq", "#attr", "#text", "#comment", etc. aren't elements
+		if strings.HasPrefix(k, "#") {
+			continue
+		}
+		list, ok := v.([]interface{})
+		if !ok {
+			list = []interface{}{v}
+		}
+		for _, lv := range list {
+			var seq int
+			if lm, ok := lv.(map[string]interface{}); ok {
+				seq, _ = lm["#seq"].(int)
+			}
+			elems = append(elems, seqElem{seq, k, lv})
+		}
+	}
+	sort.SliceStable(elems, func(i, j int) bool { return elems[i].seq < elems[j].seq })
+	return elems
+}
+
+// checkOrder reports the child elements of 'mv' that are out of struct member
+// order for the struct type 'typ'; 'seen' prevents reporting a tag more than once.
+func checkOrder(mv interface{}, typ reflect.Type, s *tagList, key string, seen map[string]bool) {
+	if s.tooDeep(key) {
+		return
+	}
+	if typ == nil {
+		return
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if isLeafType(typ) {
+		return
+	}
+	if typ.Kind() == reflect.Slice {
+		list, ok := mv.([]interface{})
+		if !ok {
+			list = []interface{}{mv}
+		}
+		for _, lv := range list {
+			if s.done {
+				return
+			}
+			checkOrder(lv, typ.Elem(), s, key, seen)
+		}
+		return
+	}
+	if typ.Kind() != reflect.Struct {
+		return
+	}
+	mm, ok := mv.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	// position of each member in the struct declaration
+	o := s.opt()
+	ts := getTypeSpec(typ, o.attrPrefix)
+	pos := make(map[*fieldSpec]int, len(ts.fields))
+	for i, f := range ts.fields {
+		pos[f] = i
+	}
+
+	var tkey string
+	var spec *fieldSpec
+	last := -1
+	for _, e := range seqElems(mm) {
+		if s.done {
+			return
+		}
+		if key == "" {
+			tkey = e.key
+		} else {
+			tkey = key + "." + e.key
+		}
+		for _, sk := range o.skiptags {
+			if tkey == sk || globMatch(sk, tkey) {
+				goto next
+			}
+		}
+		spec, ok = ts.keys[e.key]
+		if !ok {
+			// the wrapper element of `xml:"elem>sub"` members is at the
+			// position of the first member
+			if ws, wok := ts.wrappers[e.key]; wok {
+				spec, ok = ws.fields[0], true
+			}
+		}
+		if ok && !spec.skip {
+			if pos[spec] < last {
+				if !seen[tkey] {
+					seen[tkey] = true
+					s.add(tkey, typ)
+				}
+			} else {
+				last = pos[spec]
+			}
+			// a tag path, "elem>sub", isn't a member at this level of the data
+			if len(spec.tag) == 1 {
+				checkOrder(e.val, typ.FieldByIndex(spec.index).Type, s, tkey, seen)
+			}
+		}
+	next:
+	}
+}
+
+// orderByDocument sorts the tags - and their types and values - in the order in
+// which they first occur in the XML data 'b' if OrderByDocument(true) has been
+// called; tags that can't be located keep their order after the others.
+func (t *tagList) orderByDocument(b []byte) {
+	if !t.opt().docOrder || len(t.tags) < 2 || b == nil {
+		return
+	}
+	m, err := mxj.NewMapXmlSeq(skipBOM(b))
+	if err != nil {
+		return
+	}
+	var v interface{}
+	for _, v = range m {
+		break
+	}
+	pos := make(map[string]int)
+	docPositions(v, "", t.opt().attrPrefix, pos)
+
+	idx := make([]int, len(t.tags))
+	for i := range idx {
+		idx[i] = i
+	}
+	at := func(i int) int {
+		if p, ok := pos[t.tags[idx[i]]]; ok {
+			return p
+		}
+		return len(pos)
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return at(i) < at(j) })
+
+	tags := make([]string, len(idx))
+	types := make([]reflect.Type, len(idx))
+	values := make([]interface{}, len(idx))
+	for i, n := range idx {
+		tags[i], types[i], values[i] = t.tags[n], t.types[n], t.values[n]
+	}
+	t.tags, t.types, t.values = tags, types, values
+}
+
+// docPositions records the position of the first occurrence of each dot-notation
+// tag below 'key' in the mxj.NewMapXmlSeq map value 'mv'.
+func docPositions(mv interface{}, key, prefix string, pos map[string]int) {
+	mm, ok := mv.(map[string]interface{})
+	if !ok {
+		return
+	}
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + "." + k
+	}
+	if attrs, ok := mm["#attr"].(map[string]interface{}); ok {
+		names := make([]string, 0, len(attrs))
+		for k := range attrs {
+			names = append(names, k)
+		}
+		seq := func(k string) int {
+			am, _ := attrs[k].(map[string]interface{})
+			n, _ := am["#seq"].(int)
+			return n
+		}
+		sort.Slice(names, func(i, j int) bool { return seq(names[i]) < seq(names[j]) })
+		for _, k := range names {
+			if _, ok := pos[join(prefix+k)]; !ok {
+				pos[join(prefix+k)] = len(pos)
+			}
+		}
+	}
+	for _, e := range seqElems(mm) {
+		if _, ok := pos[join(e.key)]; !ok {
+			pos[join(e.key)] = len(pos)
+		}
+		docPositions(e.val, join(e.key), prefix, pos)
+	}
+}
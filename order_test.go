@@ -0,0 +1,105 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckElementOrder(t *testing.T) {
+	type item struct {
+		Name  string `xml:"name"`
+		Price string `xml:"price"`
+	}
+	type test struct {
+		ID    string `xml:"id,attr"`
+		A     string `xml:"a"`
+		B     string `xml:"b"`
+		Items []item `xml:"item"`
+		C     string `xml:"c"`
+	}
+
+	data := []byte(`<doc id="1"><a>1</a><b>2</b><item><name>x</name><price>1</price></item><item><name>y</name><price>2</price></item><c>3</c></doc>`)
+	tags, err := CheckElementOrder(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("in order:", tags)
+	}
+	// data is still complete
+	mems, _, _ := MissingXMLTags(data, test{})
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+
+	// shuffled, but complete
+	data = []byte(`<doc id="1"><b>2</b><a>1</a><item><price>1</price><name>x</name></item><c>3</c><item><price>2</price><name>y</name></item><extra/></doc>`)
+	mems, _, _ = MissingXMLTags(data, test{})
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	tags, err = CheckElementOrder(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 || tags[0] != "a" || tags[1] != "item.name" || tags[2] != "item" {
+		t.Fatal("out of order:", tags)
+	}
+
+	SetTagsToIgnore("a", "item.name")
+	defer SetTagsToIgnore()
+	tags, err = CheckElementOrder(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "item" {
+		t.Fatal("ignore:", tags)
+	}
+
+	if _, err = CheckElementOrder([]byte(`<doc><a>`), test{}); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestOrderByDocument(t *testing.T) {
+	type item struct {
+		Name string `xml:"name"`
+	}
+	type test struct {
+		Items []item `xml:"item"`
+		Title string `xml:"title"`
+	}
+	data := []byte(`<doc z="1" a="2">
+		<zeta>1</zeta>
+		<item id="1"><name>x</name><price>1</price><color>red</color></item>
+		<title>t</title>
+		<alpha>2</alpha>
+		<item><size>2</size><name>y</name></item>
+		<mid/>
+	</doc>`)
+	want := []string{"-z", "-a", "zeta", "item.-id", "item.price", "item.color", "alpha", "item.size", "mid"}
+
+	OrderByDocument(true)
+	defer OrderByDocument(false)
+	for i := 0; i < 10; i++ {
+		tags, _, err := UnknownXMLTags(data, test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tags, want) {
+			t.Fatal("unknown:", tags)
+		}
+	}
+	ut, _, _ := UnknownXMLTagsDetailed(data, test{})
+	if len(ut) != len(want) || ut[3].Path != "item.-id" || ut[3].Type != "item" {
+		t.Fatal("detailed:", ut)
+	}
+	res, _ := Check(data, test{})
+	if !reflect.DeepEqual(res.Unknown, want) {
+		t.Fatal("check:", res.Unknown)
+	}
+	v := NewValidator(reflect.TypeOf(test{}), WithOrderByDocument(false))
+	if tags, _, _ := v.Unknown(data); len(tags) != len(want) {
+		t.Fatal("validator:", tags)
+	}
+}
@@ -0,0 +1,64 @@
+// register.go - ignore lists bound to a struct type
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ignoreRegistry is a map[reflect.Type]*ignoreRule; see RegisterIgnore.
+var ignoreRegistry sync.Map
+
+// ignoreRule holds the ignore lists registered for a struct type.
+type ignoreRule struct {
+	tags    []string
+	members []skipmems
+}
+
+// RegisterIgnore binds ignore lists to the struct type 't' - e.g.,
+// reflect.TypeOf(MyStruct{}) - so they needn't be set before each check.  Whenever
+// 'val' is of type 't', or a pointer to or slice of it, the MissingXMLTags and
+// UnknownXMLTags functions, Check and a Validator for the type ignore the 'tags', as
+// if they'd been listed with SetTagsToIgnore, and the 'members', as if they'd been
+// listed with SetMembersToIgnore, in addition to the package settings.  Registering
+// the type again replaces its lists; RegisterIgnore(t, nil, nil) removes them.
+// It's safe to call RegisterIgnore concurrently with the checks.
+//
+//	Example:
+//		checkxml.RegisterIgnore(reflect.TypeOf(Config{}), []string{"comment"}, []string{"Secret"})
+func RegisterIgnore(t reflect.Type, tags, members []string) {
+	t = memberType(t)
+	if len(tags) == 0 && len(members) == 0 {
+		ignoreRegistry.Delete(t)
+		return
+	}
+	r := &ignoreRule{tags: make([]string, len(tags)), members: make([]skipmems, len(members))}
+	copy(r.tags, tags)
+	for i, v := range members {
+		r.members[i] = newSkipmems(v)
+	}
+	ignoreRegistry.Store(t, r)
+}
+
+// applyRegistered adds the ignore lists registered for the struct type 'typ', if
+// any, to the options for the traversal; it's done once, for the type of 'val'.
+func (t *tagList) applyRegistered(typ reflect.Type) {
+	if t.registered {
+		return
+	}
+	t.registered = true
+	r, ok := ignoreRegistry.Load(memberType(typ))
+	if !ok {
+		return
+	}
+	rule := r.(*ignoreRule)
+	// the options may be shared - e.g., by a Validator - so they're copied
+	o := *t.opt()
+	o.skiptags = append(append([]string(nil), o.skiptags...), rule.tags...)
+	o.skipmembers = append(append([]skipmems(nil), o.skipmembers...), rule.members...)
+	t.opts = &o
+}
@@ -0,0 +1,111 @@
+package checkxml
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+type regA struct {
+	Name   string `xml:"name"`
+	Secret string `xml:"secret"`
+}
+
+type regB struct {
+	Name   string `xml:"name"`
+	Secret string `xml:"secret"`
+}
+
+func TestRegisterIgnore(t *testing.T) {
+	data := []byte(`<doc><name>n</name><comment>c</comment><note/></doc>`)
+
+	RegisterIgnore(reflect.TypeOf(regA{}), []string{"comment"}, []string{"*.secret"})
+	defer RegisterIgnore(reflect.TypeOf(regA{}), nil, nil)
+
+	for _, val := range []interface{}{regA{}, &regA{}} {
+		mems, _, err := MissingXMLTags(data, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mems) != 0 {
+			t.Fatalf("%T missing: %v", val, mems)
+		}
+		tags, _, err := UnknownXMLTags(data, val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != 1 || tags[0] != "note" {
+			t.Fatalf("%T unknown: %v", val, tags)
+		}
+	}
+
+	// the lists are in addition to the package settings
+	SetTagsToIgnore("note")
+	res, err := Check(data, regA{})
+	SetTagsToIgnore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Missing) != 0 || len(res.Unknown) != 0 {
+		t.Fatal("check:", res.Missing, res.Unknown)
+	}
+	v := NewValidator(reflect.TypeOf(regA{}), WithTagsToIgnore("note"))
+	if ok, _ := IsValid(data, regA{}); ok {
+		t.Fatal("IsValid ignores <note>")
+	}
+	if m, u, _, _ := v.Validate(data); len(m) != 0 || len(u) != 0 {
+		t.Fatal("validator:", m, u)
+	}
+	// a Validator's own options aren't modified
+	if len(v.opts.skiptags) != 1 || len(v.opts.skipmembers) != 0 {
+		t.Fatal("validator options:", v.opts.skiptags, v.opts.skipmembers)
+	}
+
+	// a list of the type; the tags are relative to the list elements
+	list := []byte(`<list><a><name>n</name></a><a><note/></a></list>`)
+	mems, _, _ := MissingXMLTags(list, []regA{})
+	if len(mems) != 1 || mems[0] != "a.name" {
+		t.Fatal("list missing:", mems)
+	}
+
+	// the lists don't apply to another type
+	mems, _, _ = MissingXMLTags(data, regB{})
+	if len(mems) != 1 || mems[0] != "secret" {
+		t.Fatal("regB missing:", mems)
+	}
+	tags, _, _ := UnknownXMLTags(data, regB{})
+	sort.Strings(tags)
+	if len(tags) != 2 || tags[0] != "comment" {
+		t.Fatal("regB unknown:", tags)
+	}
+
+	RegisterIgnore(reflect.TypeOf(regA{}), nil, nil)
+	if mems, _, _ = MissingXMLTags(data, regA{}); len(mems) != 1 {
+		t.Fatal("removed:", mems)
+	}
+}
+
+func TestRegisterIgnoreConcurrent(t *testing.T) {
+	data := []byte(`<doc><name>n</name><comment>c</comment></doc>`)
+	defer RegisterIgnore(reflect.TypeOf(regB{}), nil, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterIgnore(reflect.TypeOf(regB{}), []string{"comment"}, []string{"secret"})
+		}()
+		go func() {
+			defer wg.Done()
+			if _, _, err := UnknownXMLTags(data, regB{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if tags, _, _ := UnknownXMLTags(data, regB{}); len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+}
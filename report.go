@@ -0,0 +1,115 @@
+// report.go - write the missing and unknown tags as a report
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/clbanning/mxj"
+)
+
+// WriteReport writes a human-readable report of the results of MissingXMLTags and
+// UnknownXMLTags for the XML data and 'val', which is of type struct, to 'w'.
+// The XML data is only decoded once.  The missing tags are listed in struct member
+// order and the unknown tags, which are found in no particular order, are sorted.
+// An error decoding the XML data is returned and nothing is written; if a check is
+// terminated with an error, e.g., ErrTruncated, the tags found up to that point are
+// written and the error is returned.
+//
+//	Example report:
+//		Root: doc
+//		Missing tags:
+//		  - elem2.another
+//		  - elem3
+//		Unknown tags:
+//		  - elem2.notes
+//		  - elem4
+func WriteReport(w io.Writer, b []byte, val interface{}) error {
+	root, _, missing, unknown, cerr := checkXMLTags(b, val, nil)
+	if missing == nil {
+		return cerr
+	}
+
+	sort.Strings(unknown.tags)
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "Root:", root)
+	for _, sect := range []struct {
+		title string
+		tags  []string
+	}{
+		{"Missing tags:", missing.tags},
+		{"Unknown tags:", unknown.tags},
+	} {
+		fmt.Fprintln(&buf, sect.title)
+		if len(sect.tags) == 0 {
+			fmt.Fprintln(&buf, "  (none)")
+		}
+		for _, tag := range sect.tags {
+			fmt.Fprintln(&buf, "  -", tag)
+		}
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return cerr
+}
+
+// checkXMLTags decodes the XML data once and does the work of both MissingXMLTags
+// and UnknownXMLTags, returning the root tag, the root value and the results as
+// tagLists.  If 'o' is nil, the package settings are used.
+func checkXMLTags(b []byte, val interface{}, o *options) (string, interface{}, *tagList, *tagList, error) {
+	if o == nil {
+		o = currentOptions()
+	}
+	m, err := mxj.NewMapXml(skipBOM(b), o.mxjCast)
+	if err != nil {
+		return "", nil, nil, nil, newParseError(err, b)
+	}
+	return checkXMLMap(m, b, val, o)
+}
+
+// checkXMLMap is checkXMLTags for the decoded XML data 'm'; 'b' is the XML data,
+// if it's available, for SetCheckRoot.  If a check is terminated with an error,
+// e.g., ErrTruncated, the tags found up to that point are returned with it.
+func checkXMLMap(m mxj.Map, b []byte, val interface{}, o *options) (string, interface{}, *tagList, *tagList, error) {
+	// strip off the root value
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	// missing tags - see missingXMLTags
+	missing, unknown := &tagList{opts: o}, &tagList{opts: o}
+	if o.firstFinding {
+		stop := func(string) bool { return false }
+		missing.fn, unknown.fn = stop, stop
+	}
+	vv, ok := v.(map[string]interface{})
+	_, isList := v.([]interface{})
+	if ok || isList {
+		checkMembers(vv, reflect.ValueOf(val), missing, "")
+		missing.checkRoot(root, b, val)
+	} else if err := missing.scalarRoot(val); err != nil {
+		return root, v, missing, unknown, err
+	}
+
+	// unknown tags - see UnknownXMLTags; there are none for a simple element
+	if (ok || isList) && !(o.firstFinding && missing.n > 0) {
+		unknown.nsDecls(b)
+		checkAllTags(v, reflect.ValueOf(val), unknown, "")
+		unknown.orderByDocument(b)
+	}
+	if missing.err != nil {
+		return root, v, missing, unknown, missing.err
+	}
+	return root, v, missing, unknown, unknown.err
+}
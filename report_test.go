@@ -0,0 +1,65 @@
+package checkxml
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteReport(t *testing.T) {
+	data := []byte(`<doc>
+	           <elem1>a simple element</elem1>
+	           <elem2>
+	             <subelem>something more complex</subelem>
+	             <notes>take a look at this</notes>
+	           </elem2>
+	           <elem4>extraneous</elem4>
+	         </doc>`)
+
+	type sub struct {
+		Subelem string `xml:"subelem,omitempty"`
+		Another string `xml:"another"`
+	}
+	type elem struct {
+		Elem1 string `xml:"elem1"`
+		Elem2 sub    `xml:"elem2"`
+		Elem3 bool   `xml:"elem3"`
+	}
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, data, elem{}); err != nil {
+		t.Fatal(err)
+	}
+	want := `Root: doc
+Missing tags:
+  - elem2.another
+  - elem3
+Unknown tags:
+  - elem2.notes
+  - elem4
+`
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteReport(&buf, []byte(`<doc><elem1>x</elem1><elem2><another/></elem2><elem3/></doc>`), elem{}); err != nil {
+		t.Fatal(err)
+	}
+	want = `Root: doc
+Missing tags:
+  (none)
+Unknown tags:
+  (none)
+`
+	if buf.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := WriteReport(&buf, []byte(`<doc><elem1>`), elem{}); err == nil {
+		t.Fatal("no error")
+	}
+	if buf.Len() != 0 {
+		t.Fatal("wrote:", buf.String())
+	}
+}
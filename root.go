@@ -0,0 +1,44 @@
+// root.go - the attributes of the XML root element
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"github.com/clbanning/mxj"
+)
+
+// RootAttrs returns the attributes of the XML root element - e.g., the version or
+// xsi:schemaLocation attributes of an envelope - keyed by their names without the
+// attribute prefix, see SetAttrPrefix, along with the XML root tag.  The values are
+// as decoded by mxj.NewMapXml; see SetMxjCast.  If the root element has no
+// attributes, an empty map is returned.  If SetAttrPrefix("") has been called, the
+// attributes can't be distinguished from child elements and nil is returned.
+//
+//	Example:
+//		attrs, root, _ := RootAttrs([]byte(`<doc version="2" lang="en"><e1/></doc>`))
+//		fmt.Println(root, attrs) // prints: doc map[lang:en version:2]
+func RootAttrs(b []byte) (map[string]interface{}, string, error) {
+	m, err := mxj.NewMapXml(skipBOM(b), mxjCast)
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+	if attrPrefix == "" {
+		return nil, root, nil
+	}
+
+	attrs := make(map[string]interface{})
+	mm, _ := v.(map[string]interface{})
+	for k, av := range mm {
+		if isAttrTag(k, attrPrefix) {
+			attrs[k[len(attrPrefix):]] = av
+		}
+	}
+	return attrs, root, nil
+}
@@ -0,0 +1,39 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRootAttrs(t *testing.T) {
+	data := []byte(`<env:Envelope version="1.2" xmlns:env="http://www.w3.org/2003/05/soap-envelope" count="3"><env:Body><item/></env:Body></env:Envelope>`)
+	attrs, root, err := RootAttrs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "Envelope" {
+		t.Fatal("root:", root)
+	}
+	if attrs["version"] != "1.2" || attrs["count"] != "3" {
+		t.Fatal("attrs:", attrs)
+	}
+
+	SetMxjCast(true)
+	defer SetMxjCast(false)
+	attrs, _, _ = RootAttrs(data)
+	if attrs["count"] != float64(3) {
+		t.Fatal("cast:", attrs)
+	}
+
+	attrs, root, err = RootAttrs([]byte(`<doc>text</doc>`))
+	if err != nil || root != "doc" || attrs == nil || len(attrs) != 0 {
+		t.Fatal("no attrs:", attrs, root, err)
+	}
+	if !reflect.DeepEqual(attrs, map[string]interface{}{}) {
+		t.Fatal("no attrs:", attrs)
+	}
+
+	if _, _, err = RootAttrs([]byte(`<doc a="1">`)); err == nil {
+		t.Fatal("no error")
+	}
+}
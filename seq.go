@@ -0,0 +1,38 @@
+// seq.go - iterators over the missing and unknown tags
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build go1.23
+
+package checkxml
+
+import (
+	"iter"
+)
+
+// MissingTagsSeq returns an iterator over the tags that MissingXMLTags reports,
+// which are found as the iterator is ranged over rather than accumulated in a
+// slice; breaking out of the range terminates the scan of the XML data.  It's
+// built on WalkMissingXMLTags.
+//
+//	Example:
+//		for tag := range checkxml.MissingTagsSeq(b, MyStruct{}) {
+//			fmt.Println("missing:", tag)
+//		}
+//
+// NOTE: an iter.Seq has no error; if the XML data can't be decoded, the sequence
+// is empty.  Use WalkMissingXMLTags if the error is needed.
+func MissingTagsSeq(b []byte, val interface{}) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		WalkMissingXMLTags(b, val, yield)
+	}
+}
+
+// UnknownTagsSeq is MissingTagsSeq for the tags that UnknownXMLTags reports; it's
+// built on WalkUnknownXMLTags.
+func UnknownTagsSeq(b []byte, val interface{}) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		WalkUnknownXMLTags(b, val, yield)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build go1.23
+
+package checkxml
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestTagsSeq(t *testing.T) {
+	type item struct {
+		Name  string `xml:"name"`
+		Price string `xml:"price"`
+	}
+	type test struct {
+		Items []item `xml:"item"`
+	}
+	data := []byte(`<doc><item><a/></item><item><b/></item><item><c/></item></doc>`)
+
+	mems, _, _ := MissingXMLTags(data, test{})
+	if got := slices.Collect(MissingTagsSeq(data, test{})); !reflect.DeepEqual(got, mems) {
+		t.Fatal("missing:", got, mems)
+	}
+	tags, _, _ := UnknownXMLTags(data, test{})
+	got := slices.Collect(UnknownTagsSeq(data, test{}))
+	sort.Strings(tags)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, tags) {
+		t.Fatal("unknown:", got, tags)
+	}
+
+	// breaking out of the range stops the traversal - if it went on calling
+	// yield, the range statement would panic
+	var n int
+	for tag := range MissingTagsSeq(data, test{}) {
+		if tag != "item.name" {
+			t.Fatal("first:", tag)
+		}
+		n++
+		break
+	}
+	for range UnknownTagsSeq(data, test{}) {
+		n++
+		break
+	}
+	if n != 2 {
+		t.Fatal("n:", n)
+	}
+
+	for tag := range MissingTagsSeq([]byte(`<doc>`), test{}) {
+		t.Fatal("bad data:", tag)
+	}
+}
@@ -0,0 +1,159 @@
+// std.go - check XML data using only the encoding/xml package to decode it
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// MissingXMLTagsStd is MissingXMLTags using an encoding/xml Decoder, rather than
+// github.com/clbanning/mxj, to decode the XML data.  The XML data is decoded into
+// the same map[string]interface{} representation that mxj.NewMapXml produces, so
+// the results are the same as for MissingXMLTags.
+func MissingXMLTagsStd(b []byte, val interface{}) ([]string, string, error) {
+	var s tagList
+
+	m, err := newMapXmlStd(bytes.NewReader(skipBOM(b)), &s)
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip off the root value
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	vv, ok := v.(map[string]interface{})
+	if !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// report the name of the value passed if not a map[string]interface{} value
+			err = s.scalarRoot(val)
+			return s.tags, root, err
+		}
+	}
+
+	checkMembers(vv, reflect.ValueOf(val), &s, "")
+	s.checkRoot(root, b, val)
+	return s.tags, root, s.err
+}
+
+// UnknownXMLTagsStd is UnknownXMLTags using an encoding/xml Decoder, rather than
+// github.com/clbanning/mxj, to decode the XML data.  The XML data is decoded into
+// the same map[string]interface{} representation that mxj.NewMapXml produces, so
+// the results are the same as for UnknownXMLTags.
+func UnknownXMLTagsStd(b []byte, val interface{}) ([]string, string, error) {
+	var s tagList
+
+	m, err := newMapXmlStd(bytes.NewReader(skipBOM(b)), &s)
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return s.tags, root, nil
+		}
+	}
+
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	s.orderByDocument(b)
+	return s.tags, root, s.err
+}
+
+// ================== decode XML data w/o mxj ...
+
+// newMapXmlStd decodes the first XML element in r as map[<root>:<value>] following
+// the mxj.NewMapXml conventions: attributes, including xmlns:prefix="..." namespace
+// declarations, are keys prepended with the attribute prefix of 's' -
+// repeated elements are a []interface{} value, the character data of an element
+// with attributes or subelements has the key "#text", and empty elements have the
+// value "".  Values are not cast; they're all string values.  Unlike mxj, a default
+// namespace declaration, xmlns="...", isn't decoded; the checks ignore it anyway.
+// The xmlns:prefix="..." declarations are recorded in 's' - see tagList.nsDecls.
+func newMapXmlStd(r io.Reader, s *tagList) (map[string]interface{}, error) {
+	d := xml.NewDecoder(r)
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if se, ok := t.(xml.StartElement); ok {
+			v, err := elemValueStd(d, se, s)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{se.Name.Local: v}, nil
+		}
+	}
+}
+
+// elemValueStd decodes the value of the element 'se' up to its xml.EndElement;
+// the attribute prefix of 's' is prepended to the attribute keys.
+func elemValueStd(d *xml.Decoder, se xml.StartElement, s *tagList) (interface{}, error) {
+	prefix := s.opt().attrPrefix
+	s.addNSDecls(se.Attr)
+	na := make(map[string]interface{})
+	for _, a := range se.Attr {
+		// a default namespace declaration, xmlns="...", isn't data; as with
+		// mxj, xmlns:prefix="..." declarations are attributes, "-prefix"
+		if a.Name.Space == "" && a.Name.Local == "xmlns" {
+			continue
+		}
+		na[prefix+a.Name.Local] = a.Value
+	}
+	var text string
+	for {
+		t, err := d.Token()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			v, err := elemValueStd(d, t, s)
+			if err != nil {
+				return nil, err
+			}
+			// If key already exists this is a list.
+			key := t.Name.Local
+			switch cur := na[key].(type) {
+			case nil:
+				na[key] = v
+			case []interface{}:
+				na[key] = append(cur, v)
+			default:
+				na[key] = []interface{}{cur, v}
+			}
+		case xml.EndElement:
+			if len(na) == 0 {
+				return text, nil // may be empty element, ""
+			}
+			if text != "" {
+				na[textKey] = text
+			}
+			return na, nil
+		case xml.CharData:
+			// clean up possible noise - as mxj does
+			if tt := strings.Trim(string(t), "\t\r\b\n "); len(tt) > 0 {
+				text = tt
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package checkxml
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/clbanning/mxj"
+)
+
+var stdData = [][]byte{
+	[]byte(`<doc><ok>true</ok><why>it's a test</why></doc>`),
+	[]byte(`<doc><ok>true</ok></doc>`),
+	[]byte(`<doc></doc>`),
+	[]byte(`<?xml version="1.0"?>
+		<doc version="2">
+			<ok>true</ok>
+			<why attr="some val">
+				<maybe>true</maybe>
+				<maybenot>false</maybenot>
+			</why>
+			<list><item>1</item><item/><item a="b">3</item></list>
+			<not>I dont't know</not>
+		</doc>`),
+}
+
+// namespaced XML data; the decoded maps differ in the default namespace
+// declaration, which mxj keeps as "-xmlns" and the checks ignore
+var stdNSData = [][]byte{
+	[]byte(`<doc xmlns="urn:doc"><ok>true</ok><more>x</more></doc>`),
+	[]byte(`<d:doc xmlns:d="urn:doc" d:version="2"><d:ok>true</d:ok></d:doc>`),
+	[]byte(`<doc xmlns:x="urn:x"><why x:attr="a" xmlns:y="urn:y"><maybe>true</maybe></why></doc>`),
+}
+
+func TestNewMapXmlStd(t *testing.T) {
+	for _, data := range stdData {
+		m, err := mxj.NewMapXml(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mstd, err := newMapXmlStd(bytes.NewReader(data), &tagList{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(map[string]interface{}(m), mstd) {
+			t.Fatalf("mxj: %v\nstd: %v", m, mstd)
+		}
+	}
+
+	if _, err := newMapXmlStd(bytes.NewReader([]byte(`<doc><ok>true</ok>`)), &tagList{}); err == nil {
+		t.Fatal("no error for truncated XML data")
+	}
+
+	// the attribute prefix is the one of the tagList, not the package setting
+	m, err := newMapXmlStd(bytes.NewReader([]byte(`<doc id="1"><e x="2"/></doc>`)), &tagList{opts: &options{attrPrefix: "@"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"doc": map[string]interface{}{"@id": "1", "e": map[string]interface{}{"@x": "2"}}}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatal("prefix:", m)
+	}
+}
+
+func TestXMLTagsStdParity(t *testing.T) {
+	type why struct {
+		Maybe bool   `xml:"maybe"`
+		Attr  string `xml:"attr,attr"`
+	}
+	type list struct {
+		Item []string `xml:"item"`
+	}
+	type test struct {
+		Ok   bool   `xml:"ok"`
+		Why  why    `xml:"why"`
+		List list   `xml:"list"`
+		More string `xml:"more"`
+	}
+
+	for _, data := range append(stdData, stdNSData...) {
+		mems, root, err := MissingXMLTags(data, test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		mstd, rstd, err := MissingXMLTagsStd(data, test{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != rstd || !sameTags(mems, mstd) {
+			t.Fatalf("missing mxj: %s %v\nmissing std: %s %v", root, mems, rstd, mstd)
+		}
+
+		tags, root, err := UnknownXMLTags(data, test{})
+		tstd, rstd, errstd := UnknownXMLTagsStd(data, test{})
+		if (err == nil) != (errstd == nil) {
+			t.Fatalf("unknown mxj err: %v, unknown std err: %v", err, errstd)
+		}
+		if root != rstd || !sameTags(tags, tstd) {
+			t.Fatalf("unknown mxj: %s %v\nunknown std: %s %v", root, tags, rstd, tstd)
+		}
+	}
+}
+
+// sameTags compares two result sets irrespective of order.
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,60 @@
+// stream.go - check a stream of concatenated XML documents
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+)
+
+// UnknownXMLTagsStream checks each of the XML documents that are concatenated in
+// the data read from 'r' - e.g., a log file with a root element per entry - against
+// the struct 'val' and calls 'fn' with the root tag and the unknown tags of each
+// document, as UnknownXMLTags reports them, until the end of the data.  Anything
+// between the documents - whitespace, comments, XML declarations - is skipped.
+// The documents are decoded with an encoding/xml Decoder, as for UnknownXMLTagsStd;
+// since the data isn't kept, the tags aren't ordered by OrderByDocument.  An error
+// decoding a document terminates the stream and is returned; 'fn' has been called
+// for the documents before it.
+//
+//	Example:
+//		err := checkxml.UnknownXMLTagsStream(f, Entry{}, func(root string, unknown []string) {
+//			if len(unknown) > 0 {
+//				log.Printf("%s: %v", root, unknown)
+//			}
+//		})
+func UnknownXMLTagsStream(r io.Reader, val interface{}, fn func(root string, unknown []string)) error {
+	o := currentOptions()
+	d := xml.NewDecoder(r)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		s := tagList{opts: o}
+		v, err := elemValueStd(d, se, &s)
+		if err != nil {
+			return err
+		}
+		// a simple element, <root>value</root>, has no unknown tags
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			checkAllTags(v, reflect.ValueOf(val), &s, "")
+		}
+		if s.err != nil {
+			return s.err
+		}
+		fn(se.Name.Local, s.tags)
+	}
+}
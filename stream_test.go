@@ -0,0 +1,73 @@
+package checkxml
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestUnknownXMLTagsStream(t *testing.T) {
+	type entry struct {
+		Level string `xml:"level,attr"`
+		Msg   string `xml:"msg"`
+	}
+	data := `<?xml version="1.0"?>
+<entry level="info"><msg>started</msg></entry>
+<!-- a comment between the documents -->
+<entry level="warn"><msg>slow</msg><ms>1500</ms><host>a</host></entry>
+<event>stopped</event>`
+
+	var roots []string
+	var unknown [][]string
+	err := UnknownXMLTagsStream(strings.NewReader(data), entry{}, func(root string, tags []string) {
+		sort.Strings(tags)
+		roots = append(roots, root)
+		unknown = append(unknown, tags)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roots, []string{"entry", "entry", "event"}) {
+		t.Fatal("roots:", roots)
+	}
+	if len(unknown[0]) != 0 || !reflect.DeepEqual(unknown[1], []string{"host", "ms"}) || len(unknown[2]) != 0 {
+		t.Fatal("unknown:", unknown)
+	}
+
+	// a malformed document ends the stream
+	var n int
+	data = `<entry><msg>a</msg></entry><entry><msg>b</entry><entry/>`
+	err = UnknownXMLTagsStream(strings.NewReader(data), entry{}, func(string, []string) { n++ })
+	if err == nil || n != 1 {
+		t.Fatal("malformed:", n, err)
+	}
+
+	// no documents
+	err = UnknownXMLTagsStream(strings.NewReader(""), entry{}, func(string, []string) { n++ })
+	if err != nil || n != 1 {
+		t.Fatal("empty:", n, err)
+	}
+}
+
+func TestUnknownXMLTagsStreamSettings(t *testing.T) {
+	type entry struct {
+		Level string `xml:"level,attr"`
+		Msg   string `xml:"msg"`
+	}
+	data := `<entry level="info"><msg>a</msg></entry><entry level="warn"><msg>b</msg></entry>`
+
+	// the settings are read when the stream is started
+	defer SetAttrPrefix("-")
+	var got [][]string
+	err := UnknownXMLTagsStream(strings.NewReader(data), entry{}, func(root string, unknown []string) {
+		got = append(got, unknown)
+		SetAttrPrefix("@")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || len(got[0]) != 0 || len(got[1]) != 0 {
+		t.Fatal("unknown:", got)
+	}
+}
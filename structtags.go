@@ -0,0 +1,257 @@
+// structtags.go - check struct definitions without reference to XML data
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// CheckStructTags returns a slice of the XML tags that are used by more than one
+// exported member at the same level of the struct definition 'val', including the
+// members promoted from embedded structs.  A member tag that's also the wrapper
+// element of another member's tag path, e.g. "a" and "a>b", is reported as "a".
+// Tags for nested structs are reported
+// using dot-notation; attribute tags are prepended with a hyphen symbol, "-", or
+// the prefix set by SetAttrPrefix, as with UnknownXMLTags.
+//
+//	Example:
+//		type doc struct {
+//			Amount   float64 `xml:"amount"`
+//			Quantity int     `xml:"amount"`
+//		}
+//
+//		fmt.Println(CheckStructTags(doc{})) // prints: [amount]
+//
+// No XML data is required; this is a lint for the struct definition itself.
+func CheckStructTags(val interface{}) []string {
+	var s []string
+	checkStructTags(reflect.TypeOf(val), &s, "", map[reflect.Type]bool{})
+	return s
+}
+
+// visited holds the struct types on the current path to handle recursive definitions.
+func checkStructTags(typ reflect.Type, s *[]string, key string, visited map[reflect.Type]bool) {
+	if typ == nil {
+		return
+	}
+	typ = memberType(typ)
+	if typ.Kind() != reflect.Struct || isLeafType(typ) || visited[typ] {
+		return
+	}
+	visited[typ] = true
+	defer delete(visited, typ)
+
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + "." + k
+	}
+	ts := getTypeSpec(typ, attrPrefix)
+	seen := make(map[string]bool, len(ts.fields))
+	var keys []string
+	for _, fs := range ts.fields {
+		if fs.skip {
+			continue
+		}
+		// members with xml tag paths, "elem>sub", can share the wrapper "elem"
+		k := fs.key
+		if len(fs.tag) > 1 {
+			k = strings.Join(fs.tag, ".")
+		}
+		if seen[k] {
+			*s = append(*s, join(k))
+			continue
+		}
+		seen[k] = true
+		keys = append(keys, k)
+		checkStructTags(typ.FieldByIndex(fs.index).Type, s, join(k), visited)
+	}
+	// but a member tag that's a wrapper of a tag path - "elem" and "elem>sub" -
+	// conflicts, as encoding/xml reports
+	for _, k := range keys {
+		for _, p := range keys {
+			if strings.HasPrefix(p, k+".") {
+				*s = append(*s, join(k))
+				break
+			}
+		}
+	}
+	// a promoted member that's hidden by a member with the same tag is a
+	// duplicate too, though encoding/xml silently ignores it
+	for _, fs := range ts.hidden {
+		*s = append(*s, join(fs.key))
+	}
+}
+
+// ValidateIgnoreList returns the SetMembersToIgnore and SetOmitemptyRequired values
+// that don't match the dot-notation tag of any member of the struct definition
+// 'val' - as MissingXMLTags reports them - so that misspelled or stale values, which
+// are otherwise silently ignored, can be found.  E.g., "a.b" is returned if the
+// member is an attribute, "a.-b".  A "*.<suffix>" value is returned if it matches
+// no member at any depth.  (SetTagsToIgnore and SetSubtreesToIgnore values are
+// for XML data tags that needn't be struct members, so they aren't checked.)
+func ValidateIgnoreList(val interface{}) []string {
+	typ := reflect.TypeOf(val)
+	// the struct types any "*.<suffix>" value may start at
+	types := make(map[reflect.Type]bool)
+	structTypes(typ, types)
+
+	found := func(v string) bool {
+		if !strings.HasPrefix(v, "*.") {
+			return hasMemberPath(typ, strings.Split(v, "."))
+		}
+		path := strings.Split(v[2:], ".")
+		for t := range types {
+			if hasMemberPath(t, path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var bad []string
+	for _, sm := range skipmembers {
+		if !found(sm.path(attrPrefix)) {
+			bad = append(bad, sm.path(attrPrefix))
+		}
+	}
+	req := make([]string, 0, len(omitemptyRequired))
+	for v := range omitemptyRequired {
+		req = append(req, v)
+	}
+	sort.Strings(req)
+	for _, v := range req {
+		if !found(v) {
+			bad = append(bad, v)
+		}
+	}
+	return bad
+}
+
+// hasMemberPath reports whether the struct type 'typ' has a member with the
+// dot-notation tag 'path', split into its elements, as checkMembers forms it.
+func hasMemberPath(typ reflect.Type, path []string) bool {
+	if typ == nil {
+		return false
+	}
+	typ = memberType(typ)
+	if typ.Kind() != reflect.Struct || isLeafType(typ) {
+		return false
+	}
+	for _, field := range getTypeSpec(typ, attrPrefix).fields {
+		k := field.key
+		if field.chardata {
+			k = textKey
+		}
+		if field.skip || k != path[0] {
+			continue
+		}
+		if len(path) == 1 || hasMemberPath(typ.FieldByIndex(field.index).Type, path[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// structTypes adds the struct type 'typ' and the struct types of its members,
+// at any depth, to 'types'.
+func structTypes(typ reflect.Type, types map[reflect.Type]bool) {
+	if typ == nil {
+		return
+	}
+	typ = memberType(typ)
+	if typ.Kind() != reflect.Struct || types[typ] || isLeafType(typ) {
+		return
+	}
+	types[typ] = true
+	for _, field := range getTypeSpec(typ, attrPrefix).fields {
+		if !field.skip {
+			structTypes(typ.FieldByIndex(field.index).Type, types)
+		}
+	}
+}
+
+// ExpectedXMLTags returns the sorted dot-notation tags that MissingXMLTags checks
+// for in the struct definition 'val' - a struct, or a pointer to or slice of a
+// struct.  Attribute tags have the attribute prefix and character data members
+// the text key, as with MissingXMLTags.  No XML data is required.  The tags of
+// "omitempty" members are included; the ignore lists aren't applied.  The members
+// of recursive struct definitions are listed to the first recursion.  An error is
+// returned if 'val' isn't a struct definition.
+func ExpectedXMLTags(val interface{}) ([]string, error) {
+	typ := reflect.TypeOf(val)
+	if typ == nil || memberType(typ).Kind() != reflect.Struct || isLeafType(memberType(typ)) {
+		return nil, fmt.Errorf("not a struct definition: %T", val)
+	}
+	paths := map[string]bool{}
+	memberPaths(typ, paths, "", map[reflect.Type]bool{})
+	tags := make([]string, 0, len(paths))
+	for t := range paths {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// DiffStructTags compares the dot-notation tags that MissingXMLTags checks for in
+// the struct definitions 'oldVal' and 'newVal' - e.g., two versions of a struct -
+// and returns the sorted tags that are only expected by 'newVal', 'added', and
+// those that are only expected by 'oldVal', 'removed'.  No XML data is required.
+// A renamed member is reported as both removed and added.  The members of
+// recursive struct definitions are listed to the first recursion.
+func DiffStructTags(oldVal, newVal interface{}) (added, removed []string) {
+	oldTags, newTags := map[string]bool{}, map[string]bool{}
+	memberPaths(reflect.TypeOf(oldVal), oldTags, "", map[reflect.Type]bool{})
+	memberPaths(reflect.TypeOf(newVal), newTags, "", map[reflect.Type]bool{})
+	for t := range newTags {
+		if !oldTags[t] {
+			added = append(added, t)
+		}
+	}
+	for t := range oldTags {
+		if !newTags[t] {
+			removed = append(removed, t)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// memberPaths adds the dot-notation tags of the members of the struct type 'typ',
+// as checkMembers forms them, to 'paths'.  'visited' holds the struct types on the
+// current path to handle recursive definitions.
+func memberPaths(typ reflect.Type, paths map[string]bool, key string, visited map[reflect.Type]bool) {
+	if typ == nil {
+		return
+	}
+	typ = memberType(typ)
+	if typ.Kind() != reflect.Struct || visited[typ] || isLeafType(typ) {
+		return
+	}
+	visited[typ] = true
+	defer delete(visited, typ)
+
+	for _, field := range getTypeSpec(typ, attrPrefix).fields {
+		ftyp := typ.FieldByIndex(field.index).Type
+		if field.skip || ftyp.Kind() == reflect.Interface {
+			continue
+		}
+		k := field.key
+		if field.chardata {
+			k = textKey
+		}
+		if key != "" {
+			k = key + "." + k
+		}
+		paths[k] = true
+		memberPaths(ftyp, paths, k, visited)
+	}
+}
@@ -0,0 +1,198 @@
+package checkxml
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestCheckStructTags(t *testing.T) {
+	// Build the struct types with reflect.StructOf, since 'go vet' rejects
+	// repeated xml tags in struct literals - which is what we're testing for.
+	str := reflect.TypeOf("")
+	sub := reflect.StructOf([]reflect.StructField{
+		{Name: "Name", Type: str, Tag: `xml:"name"`},
+		{Name: "Alias", Type: str, Tag: `xml:"name"`},
+		{Name: "ID", Type: str, Tag: `xml:"name,attr"`},
+	})
+	test := reflect.StructOf([]reflect.StructField{
+		{Name: "Amount", Type: reflect.TypeOf(float64(0)), Tag: `xml:"amount"`},
+		{Name: "Quantity", Type: reflect.TypeOf(0), Tag: `xml:"amount"`},
+		{Name: "Total", Type: reflect.TypeOf(0), Tag: `xml:"total"`},
+		{Name: "Sub", Type: sub, Tag: `xml:"sub"`},
+	})
+
+	tags := CheckStructTags(reflect.New(test).Elem().Interface())
+	if ok, v := HasTags(tags, "amount", "sub.name"); !ok {
+		t.Fatal("missing:", v)
+	}
+	if len(tags) != 2 {
+		t.Fatal("tags:", tags)
+	}
+
+	// a plain tag that's the wrapper of a tag path conflicts; encoding/xml
+	// rejects the struct
+	wrap := reflect.StructOf([]reflect.StructField{
+		{Name: "A", Type: str, Tag: `xml:"a"`},
+		{Name: "B", Type: str, Tag: `xml:"a>b"`},
+		{Name: "C", Type: str, Tag: `xml:"c>d"`},
+		{Name: "E", Type: str, Tag: `xml:"c>e"`},
+		{Name: "F", Type: str, Tag: `xml:"c>d>f"`},
+	})
+	wv := reflect.New(wrap).Interface()
+	if _, err := xml.Marshal(wv); err == nil {
+		t.Fatal("no encoding/xml error")
+	}
+	tags = CheckStructTags(reflect.New(wrap).Elem().Interface())
+	if !reflect.DeepEqual(tags, []string{"a", "c.d"}) {
+		t.Fatal("wrapper:", tags)
+	}
+
+	type clean struct {
+		A string `xml:"a"`
+		B string `xml:"b"`
+	}
+	if tags = CheckStructTags(clean{}); len(tags) != 0 {
+		t.Fatal("tags:", tags)
+	}
+
+	// the members of embedded structs are promoted, so an untagged embedded
+	// member isn't known by its type name and a promoted member can duplicate
+	// an outer one
+	type Base struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name"`
+	}
+	type extra struct {
+		Note string `xml:"note"`
+	}
+	type embedded struct {
+		Base
+		*extra
+		Ref   string `xml:"Base"`
+		Title string `xml:"name"`
+	}
+	tags = CheckStructTags(embedded{})
+	if len(tags) != 1 || tags[0] != "name" {
+		t.Fatal("embedded:", tags)
+	}
+	type same struct {
+		Base
+		Other Base `xml:"other"`
+	}
+	if tags = CheckStructTags(same{}); len(tags) != 0 {
+		t.Fatal("same:", tags)
+	}
+}
+
+func TestValidateIgnoreList(t *testing.T) {
+	type node struct {
+		Name string  `xml:"name"`
+		Kids []*node `xml:"kid"`
+	}
+	type sub struct {
+		ID   string `xml:"id,attr"`
+		Text string `xml:",chardata"`
+	}
+	type test struct {
+		A    sub    `xml:"a"`
+		Note string `xml:"note,omitempty"`
+		Tree node   `xml:"tree"`
+		Skip string `xml:"-"`
+	}
+
+	SetMembersToIgnore("a.-id", "a.id", "*.name", "*.bogus", "tree.kid.name", "a.#text", "Skip")
+	SetOmitemptyRequired("note", "notes")
+	defer func() {
+		SetMembersToIgnore()
+		SetOmitemptyRequired()
+	}()
+	bad := ValidateIgnoreList(test{})
+	want := []string{"a.id", "*.bogus", "Skip", "notes"}
+	if !reflect.DeepEqual(bad, want) {
+		t.Fatal("bad:", bad)
+	}
+
+	SetMembersToIgnore()
+	SetOmitemptyRequired()
+	if bad = ValidateIgnoreList(&test{}); len(bad) != 0 {
+		t.Fatal("none:", bad)
+	}
+}
+
+func TestDiffStructTags(t *testing.T) {
+	type subV1 struct {
+		Street string `xml:"street"`
+		City   string `xml:"city"`
+	}
+	type v1 struct {
+		ID      string `xml:"id,attr"`
+		Name    string `xml:"name"`
+		Address subV1  `xml:"address"`
+		Skip    string `xml:"-"`
+	}
+	type subV2 struct {
+		Street string `xml:"street"`
+		Town   string `xml:"town"` // renamed
+		Zip    string `xml:"zip,attr"`
+	}
+	type v2 struct {
+		ID      string `xml:"id,attr"`
+		Name    string `xml:"name"`
+		Address *subV2 `xml:"address"`
+		Any     interface{}
+	}
+
+	added, removed := DiffStructTags(v1{}, &v2{})
+	if !reflect.DeepEqual(added, []string{"address.-zip", "address.town"}) {
+		t.Fatal("added:", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"address.city"}) {
+		t.Fatal("removed:", removed)
+	}
+
+	added, removed = DiffStructTags(v1{}, v1{})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Fatal("same:", added, removed)
+	}
+}
+
+func TestExpectedXMLTags(t *testing.T) {
+	type note struct {
+		Lang string `xml:"lang,attr"`
+		Text string `xml:",chardata"`
+	}
+	type address struct {
+		Street string `xml:"street"`
+		City   string `xml:"city,omitempty"`
+		Zip    string `xml:"zip,attr"`
+	}
+	type person struct {
+		ID      string     `xml:"id,attr"`
+		Name    string     `xml:"name"`
+		Address []*address `xml:"address"`
+		Note    note       `xml:"note"`
+		Skip    string     `xml:"-"`
+		Any     interface{}
+	}
+
+	want := []string{"-id", "address", "address.-zip", "address.city", "address.street",
+		"name", "note", "note.#text", "note.-lang"}
+	tags, err := ExpectedXMLTags(person{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("expected:", tags)
+	}
+	if tags, _ = ExpectedXMLTags([]*person{}); !reflect.DeepEqual(tags, want) {
+		t.Fatal("slice:", tags)
+	}
+
+	if _, err = ExpectedXMLTags(5); err == nil {
+		t.Fatal("no error for int")
+	}
+	if _, err = ExpectedXMLTags(nil); err == nil {
+		t.Fatal("no error for nil")
+	}
+}
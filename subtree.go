@@ -0,0 +1,106 @@
+// subtree.go - check a subtree of the XML data
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// MissingXMLTagsAt is MissingXMLTags for the subtree of the XML data at the
+// dot-notation 'path' - e.g., "payload" for <doc><payload>...</payload></doc>.
+// Only the element at 'path' is checked against 'val'; the rest of the XML data
+// is ignored.  The missing tags are relative to 'path'; prepend path+"." to get
+// the full dot-notation tags.  If the element at 'path' occurs more than once,
+// each occurrence is checked and each tag is reported once.  As for the root
+// element with MissingXMLTags, a simple element, <elem>value</elem>, at 'path' is
+// reported by the name of the type of 'val', or ErrRootKind is returned if
+// StrictRootKind(true) has been called.  An error is returned if there is no
+// element at 'path'.
+//
+//	Example:
+//		data := `<envelope><header/><payload><id>1</id></payload></envelope>`
+//		tags, root, _ := MissingXMLTagsAt([]byte(data), Order{}, "payload")
+func MissingXMLTagsAt(b []byte, val interface{}, path string) ([]string, string, error) {
+	var s tagList
+
+	list, root, err := subtreeXML(b, path)
+	if err != nil {
+		return nil, root, err
+	}
+	s.once = make(map[string]bool)
+	rval := reflect.ValueOf(val)
+	for _, v := range list {
+		if !isComplex(v) {
+			if err = s.scalarRoot(val); err != nil {
+				return nil, root, err
+			}
+			continue
+		}
+		checkMembers(v, rval, &s, "")
+	}
+	return s.tags, root, s.err
+}
+
+// UnknownXMLTagsAt is UnknownXMLTags for the subtree of the XML data at the
+// dot-notation 'path'; see MissingXMLTagsAt.  A simple element at 'path' has
+// no unknown tags.
+func UnknownXMLTagsAt(b []byte, val interface{}, path string) ([]string, string, error) {
+	var s tagList
+
+	list, root, err := subtreeXML(b, path)
+	if err != nil {
+		return nil, root, err
+	}
+	s.nsDecls(b)
+	s.once = make(map[string]bool)
+	rval := reflect.ValueOf(val)
+	for _, v := range list {
+		if isComplex(v) {
+			checkAllTags(v, rval, &s, "")
+		}
+	}
+	return s.tags, root, s.err
+}
+
+// subtreeXML decodes the XML data and returns the values of the element at the
+// dot-notation 'path', relative to the root element, and the XML root tag.
+func subtreeXML(b []byte, path string) ([]interface{}, string, error) {
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip off the root value
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	for _, k := range strings.Split(path, ".") {
+		mv, ok := v.(map[string]interface{})
+		if !ok {
+			// a list can only be the last element of the path
+			return nil, root, fmt.Errorf("path not found: %s", path)
+		}
+		if v, ok = mv[k]; !ok {
+			return nil, root, fmt.Errorf("path not found: %s", path)
+		}
+	}
+	if list, ok := v.([]interface{}); ok {
+		return list, root, nil
+	}
+	return []interface{}{v}, root, nil
+}
+
+// isComplex reports whether the element value 'v' has attributes or subelements.
+func isComplex(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+	return ok
+}
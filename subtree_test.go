@@ -0,0 +1,79 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingXMLTagsAt(t *testing.T) {
+	type order struct {
+		ID    string `xml:"id,attr"`
+		Item  string `xml:"item"`
+		Price string `xml:"price"`
+	}
+	data := []byte(`<doc>
+		<header><from>me</from><to>you</to></header>
+		<body>
+			<payload id="1"><item>x</item><note>y</note></payload>
+		</body>
+	</doc>`)
+
+	tags, root, err := MissingXMLTagsAt(data, order{}, "body.payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(tags) != 1 || tags[0] != "price" {
+		t.Fatal("missing:", root, tags)
+	}
+	tags, _, err = UnknownXMLTagsAt(data, order{}, "body.payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "note" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// each occurrence is checked; each tag is reported once
+	data = []byte(`<doc><payload id="1"><item>x</item><price>1</price></payload><payload><item>y</item></payload></doc>`)
+	tags, _, err = MissingXMLTagsAt(data, order{}, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 || tags[0] != "-id" || tags[1] != "price" {
+		t.Fatal("missing list:", tags)
+	}
+	list := []byte(`<doc><payload><note>a</note></payload><payload><note>b</note><x/></payload></doc>`)
+	tags, _, _ = MissingXMLTagsAt(list, order{}, "payload")
+	if !reflect.DeepEqual(tags, []string{"-id", "item", "price"}) {
+		t.Fatal("missing once:", tags)
+	}
+	tags, _, _ = UnknownXMLTagsAt(list, order{}, "payload")
+	if !reflect.DeepEqual(tags, []string{"note", "x"}) {
+		t.Fatal("unknown once:", tags)
+	}
+
+	// a simple element is reported as for the root element
+	simple := []byte(`<doc><payload>text</payload><payload>more</payload></doc>`)
+	tags, _, err = MissingXMLTagsAt(simple, order{}, "payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "order" {
+		t.Fatal("simple:", tags)
+	}
+	tags, _, err = UnknownXMLTagsAt(simple, order{}, "payload")
+	if err != nil || len(tags) != 0 {
+		t.Fatal("simple unknown:", tags, err)
+	}
+	StrictRootKind(true)
+	defer StrictRootKind(false)
+	if tags, _, err = MissingXMLTagsAt(simple, order{}, "payload"); err != ErrRootKind || tags != nil {
+		t.Fatal("strict:", tags, err)
+	}
+
+	for _, path := range []string{"body", "payload.item.x", ""} {
+		if _, _, err = MissingXMLTagsAt(data, order{}, path); err == nil {
+			t.Fatal("no error for path:", path)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// suggest.go - suggest the member tag that an unknown tag may be a typo of
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// the maximum edit distance of a suggested tag
+var suggestThreshold = 2
+
+// SetSuggestThreshold sets the maximum edit distance - the number of characters
+// that are inserted, deleted or replaced - between an unknown tag and the member
+// tag that UnknownXMLTagsSuggest suggests for it; the default is 2.  Members whose
+// tags are farther from the unknown tag aren't suggested, so unknown tags that
+// aren't typos don't get unrelated suggestions.  SetSuggestThreshold(0) turns off
+// the suggestions; a negative value is treated as 0.
+func SetSuggestThreshold(n int) {
+	if n < 0 {
+		n = 0
+	}
+	suggestThreshold = n
+}
+
+// SuggestedTag is an unknown XML tag and the dot-notation tag of the member of
+// the enclosing struct that it's closest to, if it's within the SetSuggestThreshold
+// edit distance; otherwise Suggestion is "".
+type SuggestedTag struct {
+	Path       string
+	Suggestion string
+}
+
+// UnknownXMLTagsSuggest is UnknownXMLTags with a suggestion for each unknown tag
+// of the member tag it may be a typo of; e.g., for <nmae> in <doc><nmae>x</nmae></doc>
+// and a `xml:"name"` member, {"nmae", "name"} is returned.  Attribute tags are only
+// compared with attribute members and element tags with element members.  If more
+// than one member tag is at the least distance, the first member is suggested.
+func UnknownXMLTagsSuggest(b []byte, val interface{}) ([]SuggestedTag, string, error) {
+	return unknownXMLTagsSuggest(b, val, nil)
+}
+
+// UnknownSuggest is UnknownXMLTagsSuggest using the Validator's struct type and
+// settings.
+func (v *Validator) UnknownSuggest(b []byte) ([]SuggestedTag, string, error) {
+	return unknownXMLTagsSuggest(b, v.val, v.opts)
+}
+
+// unknownXMLTagsSuggest does the work for UnknownXMLTagsSuggest and the Validator;
+// if 'o' is nil the package settings are used.
+func unknownXMLTagsSuggest(b []byte, val interface{}, o *options) ([]SuggestedTag, string, error) {
+	s := tagList{opts: o}
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return nil, root, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	s.orderByDocument(b)
+	o = s.opt()
+	var st []SuggestedTag
+	for i, tag := range s.tags {
+		st = append(st, SuggestedTag{tag, suggestTag(tag, s.types[i], o)})
+	}
+	return st, root, s.err
+}
+
+// suggestTag returns the dot-notation tag of the member of the struct type 'typ'
+// whose tag is closest to the last element of the unknown 'tag', if it's within
+// the suggestion threshold; or "" if there's none.
+func suggestTag(tag string, typ reflect.Type, o *options) string {
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return ""
+	}
+	i := strings.LastIndex(tag, ".")
+	k := tag[i+1:]
+	var best string
+	least := o.suggestThreshold + 1
+	for _, fs := range getTypeSpec(typ, o.attrPrefix).fields {
+		if fs.skip || fs.chardata || len(fs.tag) > 1 || fs.attr != isAttrTag(k, o.attrPrefix) {
+			continue
+		}
+		if d := editDistance(k, fs.key); d < least {
+			best, least = fs.key, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return tag[:i+1] + best
+}
+
+// editDistance returns the Levenshtein distance between 'a' and 'b' - the number
+// of runes that are inserted, deleted or replaced to change one into the other.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
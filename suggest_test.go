@@ -0,0 +1,92 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnknownXMLTagsSuggest(t *testing.T) {
+	type sub struct {
+		ID     string `xml:"id,attr"`
+		Street string `xml:"street"`
+	}
+	type test struct {
+		Name    string `xml:"name"`
+		Address sub    `xml:"address"`
+		Note    string `xml:"note"`
+	}
+	data := []byte(`<doc>
+	  <nmae>a</nmae>
+	  <nam>b</nam>
+	  <address idd="1"><stret>c</stret><id>d</id></address>
+	  <comment>e</comment>
+	</doc>`)
+
+	// the default threshold, 2: "nmae" is 2 edits from "name", "comment" is
+	// farther from every member; "id" is an element, so it isn't an attribute's typo
+	want := []SuggestedTag{
+		{"nmae", "name"},
+		{"nam", "name"},
+		{"address.-idd", "address.-id"},
+		{"address.stret", "address.street"},
+		{"address.id", ""},
+		{"comment", ""},
+	}
+	OrderByDocument(true)
+	defer OrderByDocument(false)
+	st, root, err := UnknownXMLTagsSuggest(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || !reflect.DeepEqual(st, want) {
+		t.Fatal("suggest:", root, st)
+	}
+
+	// at the threshold, 1, "nmae" isn't suggested
+	SetSuggestThreshold(1)
+	defer SetSuggestThreshold(2)
+	st, _, _ = UnknownXMLTagsSuggest(data, test{})
+	want[0].Suggestion = ""
+	if !reflect.DeepEqual(st, want) {
+		t.Fatal("threshold 1:", st)
+	}
+
+	SetSuggestThreshold(0)
+	st, _, _ = UnknownXMLTagsSuggest(data, test{})
+	for _, v := range st {
+		if v.Suggestion != "" {
+			t.Fatal("threshold 0:", st)
+		}
+	}
+
+	// "comment" is 5 edits from both "name" and "note"; the first member is suggested
+	v := NewValidator(reflect.TypeOf(test{}), WithSuggestThreshold(5), WithOrderByDocument(true))
+	st, _, _ = v.UnknownSuggest(data)
+	if len(st) != 6 || st[0].Suggestion != "name" || st[5].Suggestion != "name" {
+		t.Fatal("validator:", st)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, v := range []struct {
+		a, b string
+		d    int
+	}{
+		{"", "", 0},
+		{"name", "name", 0},
+		{"", "name", 4},
+		{"nam", "name", 1},
+		{"nmae", "name", 2},
+		{"kitten", "sitting", 3},
+		{"straße", "strasse", 2},
+		{"comment", "note", 5},
+		{"comment", "name", 5},
+	} {
+		if d := editDistance(v.a, v.b); d != v.d {
+			t.Errorf("%q, %q: %d, expected %d", v.a, v.b, d, v.d)
+		}
+		if d := editDistance(v.b, v.a); d != v.d {
+			t.Errorf("%q, %q: %d, expected %d", v.b, v.a, d, v.d)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// tree.go - merge the missing and unknown tags into a tree
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Status is the state of a Node in the tree returned by BuildTree.
+type Status int
+
+const (
+	// Present - the XML element or attribute is decoded to a struct member.
+	Present Status = iota
+	// Missing - the struct member is not set by the XML data; see MissingXMLTags.
+	Missing
+	// Unknown - the XML element or attribute is not decoded; see UnknownXMLTags.
+	Unknown
+)
+
+func (s Status) String() string {
+	switch s {
+	case Present:
+		return "Present"
+	case Missing:
+		return "Missing"
+	case Unknown:
+		return "Unknown"
+	}
+	return "Status(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Node is an XML element or attribute - or a struct member - in the tree returned
+// by BuildTree.  Name is the XML tag, with attribute tags prepended with a hyphen
+// symbol, "-", or the prefix set by SetAttrPrefix, and Children are sorted by Name.
+type Node struct {
+	Name     string
+	Status   Status
+	Children []*Node
+}
+
+// BuildTree merges the results of MissingXMLTags and UnknownXMLTags for the XML
+// data and 'val', which is of type struct, into a single tree that mirrors the
+// hierarchy of the XML data and the struct definition; the root Node is the XML
+// data root tag.  Each Node is flagged as Present, Missing or Unknown; the
+// children of a Missing or Unknown Node have the same status.  Repeated
+// elements - lists - are merged into a single Node.  If a check is terminated
+// with an error, e.g., ErrTruncated, the tree of the tags found up to that point
+// is returned with it.
+//
+//	Example:
+//		n, _ := BuildTree(data, val)
+//		var show func(*Node, string)
+//		show = func(n *Node, indent string) {
+//			fmt.Println(indent+n.Name, n.Status)
+//			for _, c := range n.Children {
+//				show(c, indent+"  ")
+//			}
+//		}
+//		show(n, "")
+func BuildTree(b []byte, val interface{}) (*Node, error) {
+	// the settings are read once, so the tree is consistent
+	o := currentOptions()
+	root, v, missing, unknown, err := checkXMLTags(b, val, o)
+	if missing == nil {
+		return nil, err
+	}
+	n := &Node{Name: root, Status: Present}
+	n.addData(v)
+	for _, tag := range missing.tags {
+		n.insert(tag, Missing)
+	}
+	for _, tag := range unknown.tags {
+		n.insert(tag, Unknown).setStatus(Unknown)
+	}
+
+	n.sort()
+	return n, err
+}
+
+// addData adds the keys of the mxj.Map value 'v' to the Node hierarchy.
+func (n *Node) addData(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, cv := range v {
+			if k == textKey {
+				continue
+			}
+			n.child(k, Present).addData(cv)
+		}
+	case []interface{}:
+		for _, lv := range v {
+			n.addData(lv)
+		}
+	}
+}
+
+// child returns the child Node 'name', adding it with 'status' if necessary.
+func (n *Node) child(name string, status Status) *Node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &Node{Name: name, Status: status}
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// insert returns the Node for the dot-notation 'tag', adding any Nodes in the
+// path that don't exist with 'status'.  The Node for 'tag' is given 'status'.
+func (n *Node) insert(tag string, status Status) *Node {
+	for _, name := range strings.Split(tag, ".") {
+		n = n.child(name, status)
+	}
+	n.Status = status
+	return n
+}
+
+// setStatus sets the status of the Node hierarchy.
+func (n *Node) setStatus(status Status) {
+	n.Status = status
+	for _, c := range n.Children {
+		c.setStatus(status)
+	}
+}
+
+// sort orders the children of the Node hierarchy by Name.
+func (n *Node) sort() {
+	sort.Slice(n.Children, func(i, j int) bool { return n.Children[i].Name < n.Children[j].Name })
+	for _, c := range n.Children {
+		c.sort()
+	}
+}
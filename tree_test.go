@@ -0,0 +1,102 @@
+package checkxml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/clbanning/mxj"
+)
+
+func TestBuildTree(t *testing.T) {
+	// the README/doc.go example
+	data := `<doc>
+	           <elem1>a simple element</elem1>
+	           <elem2>
+	             <subelem>something more complex</subelem>
+	             <notes>take a look at this</notes>
+	           </elem2>
+	           <elem4>extraneous</elem4>
+	         </doc>`
+
+	type sub struct {
+		Subelem string `xml:"subelem,omitempty"`
+		Another string `xml:"another"`
+	}
+	type elem struct {
+		Elem1 string `xml:"elem1"`
+		Elem2 sub    `xml:"elem2"`
+		Elem3 bool   `xml:"elem3"`
+	}
+
+	n, err := BuildTree([]byte(data), new(elem))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	var show func(*Node, string)
+	show = func(n *Node, indent string) {
+		lines = append(lines, indent+n.Name+" "+n.Status.String())
+		for _, c := range n.Children {
+			show(c, indent+"  ")
+		}
+	}
+	show(n, "")
+	got := strings.Join(lines, "\n")
+	want := `doc Present
+  elem1 Present
+  elem2 Present
+    another Missing
+    notes Unknown
+    subelem Present
+  elem3 Missing
+  elem4 Unknown`
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// children of unknown and missing elements
+	type test struct {
+		Sub sub `xml:"sub"`
+	}
+	n, err = BuildTree([]byte(`<doc><x><y>1</y></x></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(n.Children) != 2 {
+		t.Fatal("children:", len(n.Children))
+	}
+	x, s := n.Children[1], n.Children[0]
+	if x.Name != "x" || x.Status != Unknown || len(x.Children) != 1 || x.Children[0].Status != Unknown {
+		t.Fatalf("x: %+v", x)
+	}
+	if s.Name != "sub" || s.Status != Missing || len(s.Children) != 1 || s.Children[0].Name != "another" {
+		t.Fatalf("sub: %+v", s)
+	}
+}
+
+func TestBuildTreeAttrPrefix(t *testing.T) {
+	type test struct {
+		ID   string `xml:"id,attr"`
+		Lang string `xml:"lang,attr"`
+	}
+	mxj.SetAttrPrefix("@")
+	SetAttrPrefix("@")
+	defer func() {
+		mxj.SetAttrPrefix("-")
+		SetAttrPrefix("-")
+	}()
+
+	n, err := BuildTree([]byte(`<doc id="1" x="2"/>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range n.Children {
+		got = append(got, c.Name+" "+c.Status.String())
+	}
+	want := "@id Present,@lang Missing,@x Unknown"
+	if strings.Join(got, ",") != want {
+		t.Fatal("children:", got)
+	}
+}
@@ -0,0 +1,236 @@
+// typeaudit.go - check the mxj cast value types against the struct member types
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/clbanning/mxj"
+)
+
+// TypeAuditXMLTags decodes the XML data with mxj casting - as if SetMxjCast(true)
+// had been called - and returns the dot-notation XML tags of the values whose cast
+// type, float64, bool or string, doesn't fit the kind of the struct member; e.g.,
+// <n>5</n> for a string member, <ok>yes</ok> for a bool member, or <n>1.5</n> for
+// an int member.  A bool member accepts the values 0 and 1.  An integer member is
+// checked against the value itself, as encoding/xml decodes it, so whole numbers
+// that aren't integers, e.g., <n>5.0</n> or <n>1e3</n>, are reported.  Members that are
+// decoded by an UnmarshalText or UnmarshalXML method, e.g., time.Time, aren't
+// audited.  If the XML data can't be decoded, nil is returned.
+//
+// NOTE: a string member can, of course, hold "5"; the audit reports where the XML
+// data doesn't look like what the struct definition expects.  The values are cast
+// by mxj, so its settings apply; e.g., <f>NaN</f> is reported for a float64 member
+// unless mxj.CastNanInf(true) has been called, though encoding/xml decodes it.
+func TypeAuditXMLTags(b []byte, val interface{}) []string {
+	return auditXMLTags(b, val, castFitsType)
+}
+
+// OverflowXMLTags returns the dot-notation XML tags of the numeric values that
+// are out of the range of the integer or float32 struct member they'd be decoded
+// to; e.g., <n>300</n> for an int8 or uint8 member, or <n>-1</n> for a uint member.
+// The encoding/xml Unmarshal function fails with a range error for such values;
+// the values are parsed as it parses them.  Values that aren't numbers, or that
+// aren't integers for an integer member, are reported by TypeAuditXMLTags rather
+// than OverflowXMLTags.  If the XML data can't be decoded, nil is returned.
+func OverflowXMLTags(b []byte, val interface{}) []string {
+	return auditXMLTags(b, val, inRange)
+}
+
+// UncastXMLTags decodes the XML data with mxj casting - as if SetMxjCast(true)
+// had been called - and returns the dot-notation XML tags of the values that mxj
+// left as strings where the struct member is a number or a bool; e.g., <n>abc</n>
+// or <n>1,000</n> for an int member, or <ok>yes</ok> for a bool member.  These are
+// the cast failures that TypeAuditXMLTags reports, without the numbers that don't
+// fit, like <n>1.5</n> for an int member, or the numbers in string members.  Empty
+// elements aren't reported.  If the XML data can't be decoded, nil is returned.
+func UncastXMLTags(b []byte, val interface{}) []string {
+	return auditXMLTags(b, val, notUncast)
+}
+
+// fitsFunc reports whether the XML data value fits the member type 'typ'; 'v' is
+// the value as mxj casts it and 'raw' the value as it's in the XML data.
+type fitsFunc func(v, raw interface{}, typ reflect.Type) bool
+
+// auditXMLTags decodes the XML data with and without mxj casting and returns the
+// tags of the values that don't fit their member according to 'fits'.
+func auditXMLTags(b []byte, val interface{}, fits fitsFunc) []string {
+	b = skipBOM(b)
+	m, err := mxj.NewMapXml(b, true)
+	if err != nil {
+		return nil
+	}
+	rm, _ := mxj.NewMapXml(b)
+	var v, raw interface{}
+	for root := range m {
+		v, raw = m[root], rm[root]
+	}
+	var s tagList
+	auditTypes(v, raw, reflect.ValueOf(val), &s, "", make(map[string]bool), fits)
+	return s.tags
+}
+
+// auditTypes walks the struct value 'val' and the mxj.Map values 'mv', cast, and
+// 'raw', not cast, in parallel, as checkMembers does, and reports the values that
+// don't fit the type of their member according to 'fits'; 'seen' prevents
+// reporting a tag more than once.
+func auditTypes(mv, raw interface{}, val reflect.Value, s *tagList, cmem string, seen map[string]bool, fits fitsFunc) {
+	if s.tooDeep(cmem) {
+		return
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if !val.IsValid() || isLeafType(val.Type()) {
+		return
+	}
+	typ := val.Type()
+	if typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8 {
+		list, ok := mv.([]interface{})
+		rlist, _ := raw.([]interface{})
+		if !ok {
+			list, rlist = []interface{}{mv}, []interface{}{raw}
+		}
+		sval := reflect.New(typ.Elem())
+		for i, v := range list {
+			var rv interface{}
+			if i < len(rlist) {
+				rv = rlist[i]
+			}
+			auditTypes(v, rv, sval, s, cmem, seen, fits)
+		}
+		return
+	}
+	o := s.opt()
+	if typ.Kind() != reflect.Struct {
+		// a leaf - the value may be the character data of an element with attributes
+		if mm, ok := mv.(map[string]interface{}); ok {
+			mv = mm[textKey]
+		}
+		if rm, ok := raw.(map[string]interface{}); ok {
+			raw = rm[textKey]
+		}
+		if mv != nil && !fits(mv, raw, typ) && !seen[cmem] {
+			seen[cmem] = true
+			s.add(cmem, typ)
+		}
+		return
+	}
+
+	mm, _ := mv.(map[string]interface{})
+	rm, _ := raw.(map[string]interface{})
+	for _, field := range getTypeSpec(typ, o.attrPrefix).fields {
+		if s.done {
+			return
+		}
+		if field.skip || len(field.tag) > 1 {
+			continue
+		}
+		fn := field.key
+		if field.chardata {
+			fn = textKey
+		}
+		v, ok := mm[fn]
+		rv := rm[fn]
+		if !ok && field.chardata && mm == nil {
+			v, rv, ok = mv, raw, mv != nil // a simple element
+		}
+		if !ok {
+			continue
+		}
+		tkey := fn
+		if cmem != "" {
+			tkey = cmem + "." + fn
+		}
+		auditTypes(v, rv, field.value(val), s, tkey, seen, fits)
+	}
+}
+
+// castFitsType reports whether the mxj cast value 'v' can be decoded as the kind
+// of 'typ'; an integer value is parsed from 'raw' as encoding/xml does, rather
+// than cast.
+func castFitsType(v, raw interface{}, typ reflect.Type) bool {
+	str, _ := raw.(string)
+	switch typ.Kind() {
+	case reflect.String:
+		_, ok := v.(string)
+		return ok
+	case reflect.Bool:
+		switch v := v.(type) {
+		case bool:
+			return true
+		case float64:
+			return v == 0 || v == 1
+		}
+		return false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// an out of range value is an integer - see OverflowXMLTags
+		_, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64)
+		return err == nil || isRangeErr(err)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		_, err := strconv.ParseUint(strings.TrimSpace(str), 10, 64)
+		return err == nil || isRangeErr(err)
+	case reflect.Float32, reflect.Float64:
+		_, ok := v.(float64)
+		return ok
+	}
+	return true
+}
+
+// inRange reports whether the XML data value 'raw' is in the range of the integer
+// or float32 type 'typ', parsing it as encoding/xml does; any other value or type
+// is in range.
+func inRange(v, raw interface{}, typ reflect.Type) bool {
+	str, ok := raw.(string)
+	if !ok {
+		return true
+	}
+	str = strings.TrimSpace(str)
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := strconv.ParseInt(str, 10, typ.Bits())
+		return !isRangeErr(err)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if strings.HasPrefix(str, "-") {
+			// a negative integer
+			_, err := strconv.ParseInt(str, 10, 64)
+			return err != nil && !isRangeErr(err)
+		}
+		_, err := strconv.ParseUint(str, 10, typ.Bits())
+		return !isRangeErr(err)
+	case reflect.Float32:
+		_, err := strconv.ParseFloat(str, 32)
+		return !isRangeErr(err)
+	}
+	return true
+}
+
+// notUncast reports whether the mxj cast value 'v' isn't a non-empty string for
+// a number or bool type 'typ'.
+func notUncast(v, raw interface{}, typ reflect.Type) bool {
+	if str, ok := v.(string); !ok || str == "" {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return false
+	}
+	return true
+}
+
+// isRangeErr reports whether the strconv error 'err' is a range error.
+func isRangeErr(err error) bool {
+	ne, ok := err.(*strconv.NumError)
+	return ok && ne.Err == strconv.ErrRange
+}
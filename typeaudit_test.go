@@ -0,0 +1,207 @@
+package checkxml
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/clbanning/mxj"
+)
+
+func TestTypeAuditXMLTags(t *testing.T) {
+	type sub struct {
+		Unit  string  `xml:"unit,attr"`
+		Value float64 `xml:",chardata"`
+	}
+	type test struct {
+		Code  string    `xml:"code"`
+		Ok    bool      `xml:"ok"`
+		Flag  bool      `xml:"flag"`
+		Count int       `xml:"count"`
+		Size  uint      `xml:"size"`
+		Width sub       `xml:"width"`
+		Tags  []string  `xml:"tag"`
+		When  time.Time `xml:"when"`
+	}
+
+	// number-into-string, string-into-bool and so on
+	data := []byte(`<doc>
+	  <code>5</code>
+	  <ok>yes</ok>
+	  <flag>1</flag>
+	  <count>1.5</count>
+	  <size>-1</size>
+	  <width unit="cm">abc</width>
+	  <tag>a</tag><tag>2</tag><tag>3</tag>
+	  <when>2019-05-25T10:00:00Z</when>
+	</doc>`)
+	tags := TypeAuditXMLTags(data, test{})
+	want := []string{"code", "ok", "count", "size", "width.#text", "tag"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("audit:", tags)
+	}
+
+	// all fit
+	data = []byte(`<doc><code>A5</code><ok>true</ok><count>-3</count><width unit="cm">2.5</width><tag>x</tag></doc>`)
+	if tags = TypeAuditXMLTags(data, &test{}); len(tags) != 0 {
+		t.Fatal("fit:", tags)
+	}
+	if tags = TypeAuditXMLTags([]byte(`<doc>`), test{}); tags != nil {
+		t.Fatal("bad XML:", tags)
+	}
+
+	// NaN and Inf are cast as mxj casts them
+	type float struct {
+		F float64 `xml:"f"`
+	}
+	data = []byte(`<doc><f>NaN</f></doc>`)
+	if tags = TypeAuditXMLTags(data, float{}); len(tags) != 1 || tags[0] != "f" {
+		t.Fatal("NaN:", tags)
+	}
+	if tags = UncastXMLTags(data, float{}); len(tags) != 1 || tags[0] != "f" {
+		t.Fatal("NaN uncast:", tags)
+	}
+	mxj.CastNanInf(true)
+	defer mxj.CastNanInf(false)
+	for _, f := range []string{"NaN", "Inf", "-Inf"} {
+		data = []byte(`<doc><f>` + f + `</f></doc>`)
+		if tags = TypeAuditXMLTags(data, float{}); len(tags) != 0 {
+			t.Fatal(f+":", tags)
+		}
+	}
+
+	// whole numbers that encoding/xml can't decode into an integer member
+	for _, n := range []string{"5.0", "1e3", "0x10"} {
+		data = []byte(`<doc><count>` + n + `</count><size>` + n + `</size></doc>`)
+		tags = TypeAuditXMLTags(data, test{})
+		if !reflect.DeepEqual(tags, []string{"count", "size"}) {
+			t.Fatal(n+":", tags)
+		}
+	}
+}
+
+func TestOverflowXMLTags(t *testing.T) {
+	type sub struct {
+		Unit  string `xml:"unit,attr"`
+		Value int16  `xml:",chardata"`
+	}
+	type test struct {
+		Small  int8    `xml:"small"`
+		Byte   uint8   `xml:"byte"`
+		Short  int16   `xml:"short"`
+		Word   uint16  `xml:"word"`
+		Int32  int32   `xml:"int32"`
+		Uint   uint    `xml:"uint"`
+		Int64  int64   `xml:"int64"`
+		Float  float32 `xml:"float"`
+		Width  sub     `xml:"width"`
+		Counts []int8  `xml:"count"`
+		Name   string  `xml:"name"`
+	}
+
+	// one past the range of each type
+	data := []byte(`<doc>
+	  <small>128</small>
+	  <byte>256</byte>
+	  <short>-32769</short>
+	  <word>65536</word>
+	  <int32>2147483648</int32>
+	  <uint>-1</uint>
+	  <int64>9223372036854775808</int64>
+	  <float>1e39</float>
+	  <width unit="px">40000</width>
+	  <count>1</count><count>200</count><count>300</count>
+	  <name>99999</name>
+	</doc>`)
+	tags := OverflowXMLTags(data, test{})
+	want := []string{"small", "byte", "short", "word", "int32", "uint", "int64", "float", "width.#text", "count"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("overflow:", tags)
+	}
+
+	// the limits of each type
+	data = []byte(`<doc>
+	  <small>-128</small>
+	  <byte>255</byte>
+	  <short>32767</short>
+	  <word>65535</word>
+	  <int32>-2147483648</int32>
+	  <uint>0</uint>
+	  <int64>-9223372036854775808</int64>
+	  <float>-3.4e38</float>
+	  <width unit="px">-32768</width>
+	  <count>127</count><count>-128</count>
+	  <name>99999</name>
+	</doc>`)
+	if tags = OverflowXMLTags(data, &test{}); len(tags) != 0 {
+		t.Fatal("in range:", tags)
+	}
+
+	// float32 values are parsed as encoding/xml does; 3.40282347e38 is above
+	// math.MaxFloat32, but rounds to it
+	data = []byte(`<doc><float>3.40282347e38</float></doc>`)
+	if tags = OverflowXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("rounded:", tags)
+	}
+	data = []byte(`<doc><float>3.4028236e38</float></doc>`)
+	if tags = OverflowXMLTags(data, test{}); len(tags) != 1 || tags[0] != "float" {
+		t.Fatal("float32:", tags)
+	}
+
+	// integers are compared exactly, not as float64 values
+	data = []byte(`<doc><int64>9223372036854775807</int64><uint>18446744073709551615</uint></doc>`)
+	if tags = OverflowXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("exact:", tags)
+	}
+
+	// not integers - see TypeAuditXMLTags
+	data = []byte(`<doc><small>abc</small><byte>true</byte><short>1e6</short></doc>`)
+	if tags = OverflowXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("not integers:", tags)
+	}
+	if tags = OverflowXMLTags([]byte(`<doc>`), test{}); tags != nil {
+		t.Fatal("bad XML:", tags)
+	}
+}
+
+func TestUncastXMLTags(t *testing.T) {
+	type sub struct {
+		Unit  string  `xml:"unit,attr"`
+		Value float64 `xml:",chardata"`
+	}
+	type test struct {
+		Code   string  `xml:"code"`
+		Count  int     `xml:"count"`
+		Amount float32 `xml:"amount"`
+		Ok     bool    `xml:"ok"`
+		Ratio  float64 `xml:"ratio"`
+		Width  sub     `xml:"width"`
+		Sizes  []uint  `xml:"size"`
+		Empty  int     `xml:"empty"`
+	}
+
+	data := []byte(`<doc>
+	  <code>5</code>
+	  <count>abc</count>
+	  <amount>1,000</amount>
+	  <ok>yes</ok>
+	  <ratio>1.5</ratio>
+	  <width unit="cm">wide</width>
+	  <size>1</size><size>n/a</size>
+	  <empty></empty>
+	</doc>`)
+	tags := UncastXMLTags(data, test{})
+	want := []string{"count", "amount", "ok", "width.#text", "size"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("uncast:", tags)
+	}
+
+	// numbers that don't fit aren't cast failures - see TypeAuditXMLTags
+	data = []byte(`<doc><code>A5</code><count>1.5</count><ok>1</ok><size>-1</size></doc>`)
+	if tags = UncastXMLTags(data, &test{}); len(tags) != 0 {
+		t.Fatal("cast:", tags)
+	}
+	if tags = UncastXMLTags([]byte(`<doc>`), test{}); tags != nil {
+		t.Fatal("bad XML:", tags)
+	}
+}
@@ -6,9 +6,12 @@
 package checkxml
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/clbanning/mxj"
@@ -19,7 +22,7 @@ import (
 // the XML data root tag.
 // For complex elements the tags are reported using dot-notation.
 // Attribute tags are prepended with a hyphen symbol, "-", the clbanning/mxj
-// package convention.
+// package convention.  (See SetAttrPrefix.)
 //	Examples:
 //		data1 := `<doc>
 //		            <e1>test</e1>
@@ -69,6 +72,16 @@ import (
 // as part of the unknown tags since it is "known" as part of the struct
 // definition even if it won't be decoded by the encoding/xml package.
 //
+// A struct member that isn't a struct - e.g., a string, or a []byte or a named type
+// over []byte that captures the raw content of its element, as json.RawMessage does
+// for JSON - takes the character data of its element; the encoding/xml decoder
+// ignores any child elements and attributes of the element, so they aren't reported.
+//
+// If the XML root element has no child elements or attributes - a simple element,
+// e.g., <doc>text</doc> or <doc/> - there are no unknown tags, and nil and a nil
+// error are returned; MissingXMLTags returns the name of the type of 'val' for
+// such XML data.
+//
 // NOTE: dot-notation XML tag values returned by UnknownXMLTags can be used with
 // the mxj package if the mxj.Map representation of the XML data is available.
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
@@ -83,11 +96,17 @@ import (
 //		   fmt.Printf("%s: %#v\n", tag, m.ValuesForPath(root+"."+tag))
 //		}
 func UnknownXMLTags(b []byte, val interface{}) ([]string, string, error) {
-	var s []string
+	return unknownXMLTags(b, val, nil)
+}
+
+// unknownXMLTags does the work for UnknownXMLTags; if 'o' is nil, the package
+// settings are used.
+func unknownXMLTags(b []byte, val interface{}, o *options) ([]string, string, error) {
+	s := tagList{opts: o}
 
-	m, err := mxj.NewMapXml(b)
+	m, err := mxj.NewMapXml(skipBOM(b))
 	if err != nil {
-		return nil, "", err
+		return nil, "", newParseError(err, b)
 	}
 	// strip the root tag and seed 'key'
 	var root string
@@ -98,24 +117,349 @@ func UnknownXMLTags(b []byte, val interface{}) ([]string, string, error) {
 
 	if _, ok := v.(map[string]interface{}); !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// nothing to work with just return the root key
-			return s, root, fmt.Errorf("no elements")
+			// a simple element, <root>value</root>, has no unknown tags
+			return s.tags, root, nil
 		}
 	}
 
+	s.nsDecls(b)
 	checkAllTags(v, reflect.ValueOf(val), &s, "")
-	return s, root, nil
+	s.orderByDocument(b)
+	return s.tags, root, s.err
 }
 
 // UnknownXMLTagsMap returns the mxj.Map - map[string]interface{} - representation
 // of the XML data in addition to the unknown XML tags and the XML data root tag.
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
-func UnknownXMLTagsMap(b []byte, val interface{}) ([]string, string, mxj.Map, error) {
-	var s []string
+// If 'cast' is given, cast[0] is passed to mxj.NewMapXml for the call in place of
+// the SetMxjCast setting - if true, the mxj.Map values are cast as float64 or bool
+// if possible.  It's the only mxj decoding option that's forwarded; the others,
+// e.g., mxj.PrependAttrWithHyphen, are global to the mxj package.
+func UnknownXMLTagsMap(b []byte, val interface{}, cast ...bool) ([]string, string, mxj.Map, error) {
+	var s tagList
+
+	m, err := mxj.NewMapXml(skipBOM(b), castFlag(cast))
+	if err != nil {
+		return nil, "", nil, newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return s.tags, root, m, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	s.orderByDocument(b)
+	return s.tags, root, m, s.err
+}
+
+// UnknownXMLTagsType is UnknownXMLTags for a struct definition that is only
+// available as a reflect.Type - e.g., reflect.TypeOf(MyStruct{}) - rather than
+// as a value.  If 't' is a pointer type, the struct it points to is used.
+func UnknownXMLTagsType(b []byte, t reflect.Type) ([]string, string, error) {
+	return UnknownXMLTags(b, zeroValue(t))
+}
+
+// TagValue is an unknown XML tag, in dot-notation, and its value in the mxj.Map
+// representation of the XML data.
+type TagValue struct {
+	Path  string
+	Value interface{}
+}
+
+// UnknownXMLTagsValues is UnknownXMLTags with the value of each unknown tag
+// reported along with its dot-notation path.  The values are those that
+// would be returned by mxj.Map.ValuesForPath(root+"."+Path) - without the
+// overhead of walking the mxj.Map again for each of the unknown tags.
+func UnknownXMLTagsValues(b []byte, val interface{}) ([]TagValue, string, error) {
+	var s tagList
+
+	m, err := mxj.NewMapXml(skipBOM(b), mxjCast)
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return nil, root, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	s.orderByDocument(b)
+	var tv []TagValue
+	for i, tag := range s.tags {
+		tv = append(tv, TagValue{tag, s.values[i]})
+	}
+	return tv, root, s.err
+}
+
+// DeprecatedXMLTags returns the dot-notation tags in the XML data that are listed
+// with SetDeprecatedTags, in no particular order, along with the XML root tag.  The
+// tags aren't reported by UnknownXMLTags; Check reports both.
+func DeprecatedXMLTags(b []byte, val interface{}) ([]string, string, error) {
+	var s tagList
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no tags
+			return nil, root, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	return s.deprecated, root, s.err
+}
+
+// UnknownValueLen is the maximum length, in characters, of UnknownTagError.Value.
+const UnknownValueLen = 32
+
+// UnknownTagError is returned by UnknownXMLTagsStrict for XML data with unknown
+// tags.  Tag is the first of them, in dot-notation, and Value is its value as a
+// string - truncated to UnknownValueLen characters, with "..." appended - or
+// "(complex element)" for an element with attributes or child elements and
+// "(repeated element)" for an element that occurs more than once.  Count is the
+// number of unknown tags.
+type UnknownTagError struct {
+	Tag   string
+	Value string
+	Count int
+}
+
+func (e *UnknownTagError) Error() string {
+	msg := fmt.Sprintf("unknown tag %q with value %s", e.Tag, e.Value)
+	if e.Count > 1 {
+		msg += fmt.Sprintf(" (and %d more)", e.Count-1)
+	}
+	return msg
+}
+
+// UnknownXMLTagsStrict is UnknownXMLTags for XML data that must not have any
+// unknown tags: if it has, an *UnknownTagError that describes the first of them
+// is returned along with the XML root tag; e.g., for logging:
+//
+//	unknown tag "item.qty" with value "12" (and 2 more)
+func UnknownXMLTagsStrict(b []byte, val interface{}) (string, error) {
+	tv, root, err := UnknownXMLTagsValues(b, val)
+	if err != nil {
+		return root, err
+	}
+	if len(tv) == 0 {
+		return root, nil
+	}
+	return root, &UnknownTagError{Tag: tv[0].Path, Value: valueString(tv[0].Value), Count: len(tv)}
+}
+
+// valueString returns the mxj.Map value 'v' as a quoted string of at most
+// UnknownValueLen characters, or a description of a value that isn't simple.
+func valueString(v interface{}) string {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return "(complex element)"
+	case []interface{}:
+		return "(repeated element)"
+	case string:
+		r := []rune(strings.TrimSpace(v))
+		if len(r) > UnknownValueLen {
+			return strconv.Quote(string(r[:UnknownValueLen]) + "...")
+		}
+		return strconv.Quote(string(r))
+	}
+	return fmt.Sprint(v)
+}
+
+// UnknownTag is an unknown XML tag, in dot-notation, and the name of the struct
+// type - the type of 'val' or of one of its members - at the level of the struct
+// definition where the tag was encountered.  Depth is the number of elements in
+// Path - 1 for a child element or attribute of the root element; see TagDepth.
+type UnknownTag struct {
+	Path  string
+	Type  string
+	Depth int
+}
+
+// UnknownXMLTagsDetailed is UnknownXMLTags with the name of the struct type that
+// would have had to define each unknown tag reported along with the tag.
+func UnknownXMLTagsDetailed(b []byte, val interface{}) ([]UnknownTag, string, error) {
+	var s tagList
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return nil, root, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	s.orderByDocument(b)
+	var ut []UnknownTag
+	for i, tag := range s.tags {
+		ut = append(ut, UnknownTag{tag, s.types[i].Name(), TagDepth(tag)})
+	}
+	return ut, root, s.err
+}
+
+// CanonicalTag is an unknown XML tag and, if it differs only by case from the tag
+// of a member of the enclosing struct - or matches it with the SetNameNormalizer
+// function - the dot-notation tag of that member; otherwise Canonical is "".
+type CanonicalTag struct {
+	Path      string
+	Canonical string
+}
+
+// UnknownXMLTagsCanonical is UnknownXMLTags with the tag of the struct member that
+// each unknown tag was probably meant to be, if there's one; e.g., for <Name> in
+// <doc><Name>x</Name></doc> and a `xml:"name"` member, {"Name", "name"} is returned.
+func UnknownXMLTagsCanonical(b []byte, val interface{}) ([]CanonicalTag, string, error) {
+	var s tagList
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return nil, "", newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return nil, root, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	s.orderByDocument(b)
+	o := s.opt()
+	var ct []CanonicalTag
+	for i, tag := range s.tags {
+		ct = append(ct, CanonicalTag{tag, canonicalTag(tag, s.types[i], o)})
+	}
+	return ct, root, s.err
+}
+
+// canonicalTag returns the dot-notation tag of the member of the struct type 'typ'
+// that the last element of the unknown 'tag' matches but for case, or with the
+// name normalizer; or "" if there's none.
+func canonicalTag(tag string, typ reflect.Type, o *options) string {
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return ""
+	}
+	i := strings.LastIndex(tag, ".")
+	k := tag[i+1:]
+	for _, fs := range getTypeSpec(typ, o.attrPrefix).fields {
+		if fs.skip || fs.chardata || len(fs.tag) > 1 || fs.attr != isAttrTag(k, o.attrPrefix) {
+			continue
+		}
+		if strings.EqualFold(fs.key, k) || (o.looseNames() && o.normalKey(fs.key) == o.dataKey(k)) {
+			return tag[:i+1] + fs.key
+		}
+	}
+	return ""
+}
+
+// WalkUnknownXMLTags calls fn with each unknown XML tag, in dot-notation, as it is
+// found rather than accumulating them in a slice.  If fn returns false the scan of
+// the XML data is terminated.  The XML data root tag is returned.
+func WalkUnknownXMLTags(b []byte, val interface{}, fn func(tag string) bool) (string, error) {
+	return walkUnknownXMLTags(b, val, fn, nil)
+}
+
+// walkUnknownXMLTags does the work for WalkUnknownXMLTags; if 'o' is nil, the
+// package settings are used.
+func walkUnknownXMLTags(b []byte, val interface{}, fn func(tag string) bool, o *options) (string, error) {
+	s := tagList{fn: fn, opts: o}
+
+	m, err := mxj.NewMapXml(skipBOM(b))
+	if err != nil {
+		return "", newParseError(err, b)
+	}
+	// strip the root tag and seed 'key'
+	var root string
+	var v interface{}
+	for root, v = range m {
+		break
+	}
+
+	if _, ok := v.(map[string]interface{}); !ok {
+		if _, ok = v.([]interface{}); !ok {
+			// a simple element, <root>value</root>, has no unknown tags
+			return root, nil
+		}
+	}
+
+	s.nsDecls(b)
+	checkAllTags(v, reflect.ValueOf(val), &s, "")
+	return root, s.err
+}
+
+// HasUnknownAttrs reports whether the XML data has any attributes that will not
+// be decoded to a member of 'val'.  The scan of the XML data stops at the first
+// unknown attribute that is found.
+func HasUnknownAttrs(b []byte, val interface{}) (bool, error) {
+	var found bool
+	o := currentOptions()
+	_, err := walkUnknownXMLTags(b, val, func(tag string) bool {
+		found = isAttrTag(tag, o.attrPrefix)
+		return !found
+	}, o)
+	if err != nil && !found {
+		return false, err
+	}
+	return found, nil
+}
+
+// UnexportedXMLTags returns the XML tags, in dot-notation, that match the name of
+// an unexported member of 'val' and so will not be decoded by the encoding/xml
+// Unmarshal function.  (See ReportUnexportedAsKnown.)
+func UnexportedXMLTags(b []byte, val interface{}) ([]string, string, error) {
+	var s tagList
 
-	m, err := mxj.NewMapXml(b, mxjCast)
+	m, err := mxj.NewMapXml(skipBOM(b))
 	if err != nil {
-		return nil, "", nil, err
+		return nil, "", newParseError(err, b)
 	}
 	// strip the root tag and seed 'key'
 	var root string
@@ -123,15 +467,69 @@ func UnknownXMLTagsMap(b []byte, val interface{}) ([]string, string, mxj.Map, er
 	for root, v = range m {
 		break
 	}
+
 	if _, ok := v.(map[string]interface{}); !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// nothing to work with just return the root key
-			return s, root, m, fmt.Errorf("no elements")
+			// a simple element, <root>value</root>, has no unknown tags
+			return nil, root, nil
 		}
 	}
 
+	s.nsDecls(b)
 	checkAllTags(v, reflect.ValueOf(val), &s, "")
-	return s, root, m, nil
+	return s.unexported, root, s.err
+}
+
+// UnknownXMLTagsGrouped is UnknownXMLTags with the result grouped by parent
+// element: the map keys are the dot-notation tags of the parent elements - ""
+// for the XML root element - and the values are the sorted list of unknown tag
+// names under each parent.  E.g., for the unknown tags "a.x", "a.-y" and "z" the
+// result is map["":[z] a:[-y x]].
+func UnknownXMLTagsGrouped(b []byte, val interface{}) (map[string][]string, string, error) {
+	tags, root, err := UnknownXMLTags(b, val)
+	if tags == nil {
+		return nil, root, err
+	}
+	groups := make(map[string][]string)
+	for _, t := range tags {
+		n := strings.LastIndex(t, ".")
+		parent := ""
+		if n >= 0 {
+			parent = t[:n]
+		}
+		groups[parent] = append(groups[parent], t[n+1:])
+	}
+	for _, g := range groups {
+		sort.Strings(g)
+	}
+	return groups, root, err
+}
+
+// UnknownXMLTagsSplit is UnknownXMLTags with the result partitioned into unknown
+// element tags and unknown attribute tags. A tag is an attribute if the last
+// segment of its dot-notation path begins with the attribute prefix, "-"; the
+// attribute tags retain the path of the parent element - e.g., "e1.-attr".  If the
+// check is terminated with an error, e.g., ErrTruncated, the tags found up to that
+// point are returned with it, as for UnknownXMLTags.
+func UnknownXMLTagsSplit(b []byte, val interface{}) ([]string, []string, string, error) {
+	o := currentOptions()
+	tags, root, err := unknownXMLTags(b, val, o)
+	elems, attrs := splitTags(tags, o.attrPrefix)
+	return elems, attrs, root, err
+}
+
+// splitTags partitions dot-notation tags into element and attribute tags; 'prefix'
+// is the attribute prefix.
+func splitTags(tags []string, prefix string) ([]string, []string) {
+	var elems, attrs []string
+	for _, t := range tags {
+		if isAttrTag(t, prefix) {
+			attrs = append(attrs, t)
+		} else {
+			elems = append(elems, t)
+		}
+	}
+	return elems, attrs
 }
 
 // ================= io.Reader functions ...
@@ -140,10 +538,20 @@ func UnknownXMLTagsMap(b []byte, val interface{}) ([]string, string, mxj.Map, er
 // the XML tags that are unknown with respect to the struct 'val' and the XML data
 // root tag.
 func UnknownXMLTagsReader(r io.Reader, val interface{}) ([]string, string, error) {
-	var s []string
+	return UnknownXMLTagsReaderContext(context.Background(), r, val)
+}
+
+// UnknownXMLTagsReaderContext is UnknownXMLTagsReader with a Context.  If the Context is done
+// while the XML data is being read or checked, ctx.Err() is returned.  See also
+// SetMaxReadSize.
+func UnknownXMLTagsReaderContext(ctx context.Context, r io.Reader, val interface{}) ([]string, string, error) {
+	s := tagList{ctx: ctx}
+	cr := newCtxReader(ctx, r, s.opt().maxReadSize)
 
-	m, err := mxj.NewMapXmlReader(r)
+	// the raw XML data is kept for the namespace declarations - see tagList.nsDecls
+	m, raw, err := mxj.NewMapXmlReaderRaw(cr)
 	if err != nil {
+		err = cr.readErr(err)
 		return nil, "", err
 	}
 	// strip the root tag and seed 'key'
@@ -155,24 +563,36 @@ func UnknownXMLTagsReader(r io.Reader, val interface{}) ([]string, string, error
 
 	if _, ok := v.(map[string]interface{}); !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// nothing to work with just return the root key
-			return s, root, fmt.Errorf("no elements")
+			// a simple element, <root>value</root>, has no unknown tags
+			return s.tags, root, nil
 		}
 	}
 
+	s.nsDecls(raw)
 	checkAllTags(v, reflect.ValueOf(val), &s, "")
-	return s, root, nil
+	return s.tags, root, s.err
 }
 
 // UnknownXMLTagsReaderMap consumes the XML data from an io.Reader and returns
 // the mxj.Map - map[string]interface{} - representation of the XML data in addition
 // to the unknown XML tags and the XML data root tag. 
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
-func UnknownXMLTagsReaderMap(r io.Reader, val interface{}) ([]string, string, mxj.Map, error) {
-	var s []string
+// 'cast' is as for UnknownXMLTagsMap.
+func UnknownXMLTagsReaderMap(r io.Reader, val interface{}, cast ...bool) ([]string, string, mxj.Map, error) {
+	return UnknownXMLTagsReaderMapContext(context.Background(), r, val, cast...)
+}
+
+// UnknownXMLTagsReaderMapContext is UnknownXMLTagsReaderMap with a Context.  If the Context is done
+// while the XML data is being read or checked, ctx.Err() is returned.  See also
+// SetMaxReadSize.
+func UnknownXMLTagsReaderMapContext(ctx context.Context, r io.Reader, val interface{}, cast ...bool) ([]string, string, mxj.Map, error) {
+	s := tagList{ctx: ctx}
+	cr := newCtxReader(ctx, r, s.opt().maxReadSize)
 
-	m, err := mxj.NewMapXmlReader(r, mxjCast)
+	// the raw XML data is kept for the namespace declarations - see tagList.nsDecls
+	m, raw, err := mxj.NewMapXmlReaderRaw(cr, castFlag(cast))
 	if err != nil {
+		err = cr.readErr(err)
 		return nil, "", m, err
 	}
 	// strip the root tag and seed 'key'
@@ -184,13 +604,14 @@ func UnknownXMLTagsReaderMap(r io.Reader, val interface{}) ([]string, string, mx
 
 	if _, ok := v.(map[string]interface{}); !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// nothing to work with just return the root key
-			return s, root, m, fmt.Errorf("no elements")
+			// a simple element, <root>value</root>, has no unknown tags
+			return s.tags, root, m, nil
 		}
 	}
 
+	s.nsDecls(raw)
 	checkAllTags(v, reflect.ValueOf(val), &s, "")
-	return s, root, m, nil
+	return s.tags, root, m, s.err
 }
 
 // UnknownXMLTagsReaderMapRaw consumes the XML data from an io.Reader and returns
@@ -198,11 +619,22 @@ func UnknownXMLTagsReaderMap(r io.Reader, val interface{}) ([]string, string, mx
 // the mxj.Map - map[string]interface{} - representation of the XML data, and the XML
 // data root tag.
 // (See github.com/clbanning/mxj documentation of mxj.Map type.)
-func UnknownXMLTagsReaderMapRaw(r io.Reader, val interface{}) ([]string, string, mxj.Map, []byte, error) {
-	var s []string
+// 'cast' is as for UnknownXMLTagsMap.
+func UnknownXMLTagsReaderMapRaw(r io.Reader, val interface{}, cast ...bool) ([]string, string, mxj.Map, []byte, error) {
+	return UnknownXMLTagsReaderMapRawContext(context.Background(), r, val, cast...)
+}
 
-	m, raw, err := mxj.NewMapXmlReaderRaw(r, mxjCast)
+// UnknownXMLTagsReaderMapRawContext is UnknownXMLTagsReaderMapRaw with a Context.  If the Context is done
+// while the XML data is being read or checked, ctx.Err() is returned.  See also
+// SetMaxReadSize.
+// The raw XML data that was read before cancellation is still returned.
+func UnknownXMLTagsReaderMapRawContext(ctx context.Context, r io.Reader, val interface{}, cast ...bool) ([]string, string, mxj.Map, []byte, error) {
+	s := tagList{ctx: ctx}
+	cr := newCtxReader(ctx, r, s.opt().maxReadSize)
+
+	m, raw, err := mxj.NewMapXmlReaderRaw(cr, castFlag(cast))
 	if err != nil {
+		err = cr.readErr(err)
 		return nil, "", m, raw, err
 	}
 	// strip the root tag and seed 'key'
@@ -213,21 +645,29 @@ func UnknownXMLTagsReaderMapRaw(r io.Reader, val interface{}) ([]string, string,
 	}
 	if _, ok := v.(map[string]interface{}); !ok {
 		if _, ok = v.([]interface{}); !ok {
-			// nothing to work with just return the root key
-			return s, root, m, raw, fmt.Errorf("no elements")
+			// a simple element, <root>value</root>, has no unknown tags
+			return s.tags, root, m, raw, nil
 		}
 	}
+	s.nsDecls(raw)
 	checkAllTags(v, reflect.ValueOf(val), &s, "")
-	return s, root, m, raw, nil
+	s.orderByDocument(raw)
+	return s.tags, root, m, raw, s.err
 }
 
 // ================== where the work is done ...
 
-func checkAllTags(mv interface{}, val reflect.Value, s *[]string, key string) {
-	var tkey string
+func checkAllTags(mv interface{}, val reflect.Value, s *tagList, key string) {
+	if s.tooDeep(key) || s.canceled() {
+		return
+	}
 
-	// 1. Convert any pointer value.
+	// 1. Convert any pointer value.  A nil pointer - e.g., a *[]Item member - is
+	//    replaced by a pointer to a zero value if there's XML data to check it against.
 	if val.Kind() == reflect.Ptr {
+		if val.IsNil() && mv != nil {
+			val = reflect.New(val.Type().Elem())
+		}
 		val = reflect.Indirect(val)
 	}
 	// zero Value?
@@ -235,6 +675,13 @@ func checkAllTags(mv interface{}, val reflect.Value, s *[]string, key string) {
 		return
 	}
 	typ := val.Type()
+	// Values decoded by UnmarshalText or UnmarshalXML, like time.Time, are leaves.
+	if isLeafType(typ) {
+		return
+	}
+	if key == "" {
+		s.applyRegistered(typ)
+	}
 
 	// 2. If its a slice then 'mv' should hold a []interface{} value.
 	//    Loop through the members of 'mv' and see that they are valid relative
@@ -247,6 +694,27 @@ func checkAllTags(mv interface{}, val reflect.Value, s *[]string, key string) {
 		// slice may be nil, so create a Value of it's type
 		// 'mv' must be of type []interface{}
 		sval := reflect.New(tval)
+		// A top-level list - 'val' is a slice - is checked against the child
+		// elements of the root element; the root element can't have attributes.
+		if key == "" {
+			o := s.opt()
+			if mm, ok := mv.(map[string]interface{}); ok {
+				for k, v := range mm {
+					if isAttrTag(k, o.attrPrefix) && !s.isNSDecl(k) {
+						s.addValue(k, typ, v)
+					}
+				}
+			}
+			for _, c := range rootChildren(mv, o) {
+				for _, cv := range c.list {
+					if s.done {
+						return
+					}
+					checkAllTags(cv, sval, s, c.tag)
+				}
+			}
+			return
+		}
 		slice, ok := mv.([]interface{})
 		if !ok {
 			// See if there's a singleton, not a slice, in XML object.
@@ -258,126 +726,130 @@ func checkAllTags(mv interface{}, val reflect.Value, s *[]string, key string) {
 		// 2.1. Check members of XML data
 		//      This forces all of them to be regular and w/o typos in key labels.
 		for _, sl := range slice {
+			if s.done {
+				return
+			}
 			checkAllTags(sl, sval, s, key) // all list elements have same tag
 		}
 		return
 	}
 
-	// 3a. Ignore anything that's not a struct.
+	// 3a. Ignore anything that's not a struct.  So an interface{} member is a
+	//     catch-all for any XML data; nothing in its element is unknown.
 	if typ.Kind() != reflect.Struct {
 		return // just ignore it - don't look for k:v pairs
 	}
-	// 3b. map value must represent k:v pairs
+	// 3b. Get the map of struct field name:fieldSpec - the xml tag, if there is
+	//    one, is used instead of the field label to insure that the spec'd tag
+	//    matches the XML tag exactly. (See newTypeSpec().)
+	//    A xml tag path, e.g., "elem>sub>stuff", is handled as the wrapper
+	//    element "elem" - see checkKeys.
+	ts := getTypeSpec(typ, s.opt().attrPrefix)
+
+	// 4. map value must represent k:v pairs, unless it's a simple element
+	//    that is decoded to a ",chardata" member or an empty element, <elem/>,
+	//    which is decoded as a zero value.
 	mm, ok := mv.(map[string]interface{})
-	if !ok {
-		*s = append(*s, key)
-	}
-
-	// 4. Build the map of struct field name:fieldSpec
-	//    We make every key (field) label look like an exported label - "Fieldname".
-	//    If there is a XML tag it is used instead of the field label, and saved to
-	//    insure that the spec'd tag matches the XML tag exactly.
-	type fieldSpec struct {
-		val reflect.Value
-		tag []string // tag may be a path
-	}
-	fieldCnt := val.NumField()
-	fields := make(map[string]*fieldSpec, fieldCnt)
-	for i := 0; i < fieldCnt; i++ {
-		if len(typ.Field(i).PkgPath) > 0 {
-			continue // field is NOT exported
-		}
-		// Ignore xml.Name type fields - they don't appear in the map mm.
-		// The root label is handed in as "key" in the initial call.
-		if typ.Field(i).Type.Name() == "Name" && typ.Field(i).Type.PkgPath() == "encoding/xml" {
-			continue
-		}
-		// Get tag and attr info from member spec
-		// A go xml tag may be a single label, e.g., "elem",
-		// or it may be a path to a subelement, e.g., "elem>sub>stuff",
-		// see: https://golang.org/pkg/encoding/xml/#example_Unmarshal.
-		// We just ignore the rest of the path for now - see discussion below in #5.
-		attr := false
-		tagvals := typ.Field(i).Tag.Get("xml")
-		tags := strings.Split(tagvals, ",")
-		tag := strings.Split(tags[0], ">")
-		// Fields with "-" might, validly, be there
-		// so allow the field name to be included.
-		if tag[0] == "-" {
-			tag = []string{""}
-		}
-		// See if struct member is an attribute value.
-		for _, v := range tags[1:] {
-			if v == "attr" {
-				attr = true
-				break
-			}
-		}
-		// If attr==true then the mm key will be prepended with "-"
-		// so the Field name and the 'tag' value must be prepended with "-"
-		// to match the decoded value.
-		// NOTE: the xml decoder requires that elem/attr tags match exactly
-		// the public member name or its xml tag label; unlike json decoder
-		// there is no coersion of lower case element tags to public
-		// member names.
-		switch attr {
-		case false:
-			if tag[0] == "" {
-				fields[typ.Field(i).Name] = &fieldSpec{val.Field(i), tag}
-			} else {
-				fields[tag[0]] = &fieldSpec{val.Field(i), tag}
-			}
-		case true:
-			if tag[0] == "" {
-				fields["-"+typ.Field(i).Name] = &fieldSpec{val.Field(i), tag}
-			} else {
-				fields["-"+tag[0]] = &fieldSpec{val.Field(i), tag}
-			}
-		}
+	if !ok && !ts.chardata && mv != "" {
+		s.addValue(key, typ, mv)
 	}
 
 	// 5. check that map keys correspond to exported field names
-	//    We handle the keys in the map literally, unlike for encoding/json.
-	//    But first remove any "#text" keys if it's a simple element.
-	delete(mm, "#text")
+	checkKeys(mm, val, ts, s, key)
+}
 
+// checkKeys checks the keys of 'mm' against the fieldSpecs in 'ts' for the
+// struct 'val'.  If 'ts' is a wrapper spec, the keys are the subelements of
+// a wrapper element of `xml:"elem>sub"` member tags.
+func checkKeys(mm map[string]interface{}, val reflect.Value, ts *typeSpec, s *tagList, key string) {
+	var tkey string
 	var spec *fieldSpec
+	var ok bool
+	typ := val.Type()
+	o := s.opt()
+
+	// We handle the keys in the map literally, unlike for encoding/json.
+	// But skip the text key, "#text", - it's the character data of the
+	// element, whether or not it is decoded.  The map isn't modified,
+	// since it may be returned to the caller.
 	for k, m := range mm {
-		for _, sk := range skiptags {
-			if key == "" && k == sk {
-				goto next
-			} else if key != "" && key+"."+k == sk {
-				goto next
-			}
+		if s.done {
+			return
+		}
+		if k == textKey {
+			continue
 		}
-		// used for !ok and recursion on checkAllTags
+		// used for skiptags, !ok and recursion on checkAllTags
 		if key == "" {
 			tkey = k
 		} else {
 			tkey = key + "." + k
 		}
-		spec, ok = fields[k]
-		if !ok {
-			*s = append(*s, tkey)
+		for _, sk := range o.skiptags {
+			if tkey == sk || globMatch(sk, tkey) {
+				goto next
+			}
+		}
+		if len(o.skipsubtrees) > 0 && inSubtree(tkey, o.skipsubtrees) {
+			continue
+		}
+		if len(o.deprecated) > 0 && matchTag(tkey, o.deprecated) {
+			s.addDeprecated(tkey)
+			continue
+		}
+		if o.ignoreAttrs && isAttrTag(k, o.attrPrefix) {
+			continue
+		}
+		// namespace declarations, xmlns="..." and xmlns:prefix="...", aren't data
+		if s.isNSDecl(k) {
 			continue
 		}
-		// todo(clb): resolve how to handle subelement xml tags.
-		// Do we even need to for unknown tags? -
-		// Perhaps not, as the decoder must be able to walk the path per the struct
-		// definition.  MissingXMLTags() can be used to see if the desired path can
-		// be walked in the XML data, that result can then be used to see if the
-		// desired subelement path is in the XML data. Something like:
-		// 	subelemtag = "doc.elem.text" // we've replace ">" with "."
-		// 	mems, _ := MissingXMLTags(...)
-		// 	for _, v := range mems {
-		// 		if subelemtag == v {
-		// 			fmt.Println("subelement xml tag does not exist in XML data:", subelemtag)
-		// 		}
-		// 	}
-		//
-		checkAllTags(m, spec.val, s, tkey)
+		spec, ok = ts.keys[k]
+		if !ok && o.looseNames() {
+			// as for checkMembers, use the first matching key in sort order
+			nk := o.dataKey(k)
+			var mk string
+			for fk, fs := range ts.keys {
+				if o.normalKey(fk) == nk && (!ok || fk < mk) {
+					spec, ok, mk = fs, true, fk
+				}
+			}
+		}
+		if !ok && o.attrOrElement && o.attrPrefix != "" {
+			// only if the member's own kind of data isn't there - see MatchAttrOrElement
+			other := o.otherKind(k, isAttrTag(k, o.attrPrefix))
+			if _, dup := mm[other]; !dup {
+				spec, ok = ts.keys[other]
+			}
+		}
+		if ok {
+			// there's nothing below the element of a flat struct's member
+			if !ts.flat || o.maxDepth > 0 {
+				checkAllTags(m, spec.value(val), s, tkey)
+			}
+			continue
+		}
+		// A wrapper element, "elem", for the `xml:"elem>sub"` member tags has
+		// no member of its own; the subelements are checked against the tag paths.
+		if ws, ok := ts.wrappers[k]; ok {
+			list, ok := m.([]interface{})
+			if !ok {
+				list = []interface{}{m}
+			}
+			for _, lm := range list {
+				if wm, ok := lm.(map[string]interface{}); ok {
+					checkKeys(wm, val, ws, s, tkey)
+				}
+			}
+			continue
+		}
+		if ts.unexported[k] {
+			s.unexported = append(s.unexported, tkey)
+			if o.unexportedKnown {
+				continue
+			}
+		}
+		s.addValue(tkey, typ, m)
 	next:
 	}
-
-	return
 }
@@ -2,9 +2,18 @@ package checkxml
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	// "fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/clbanning/mxj"
 )
 
 func TestUnknownXMLTags(t *testing.T) {
@@ -147,6 +156,55 @@ func TestUnknownXMLTagsReader(t *testing.T) {
 	}
 }
 
+func TestUnknownXMLTagsReaderContext(t *testing.T) {
+	type test struct {
+		Why string `xml:"why"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := UnknownXMLTagsReaderContext(ctx, &slowReader{}, test{}); err != context.DeadlineExceeded {
+		t.Fatal("reader:", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, _, err := UnknownXMLTagsReaderMapContext(ctx, &slowReader{}, test{}); err != context.DeadlineExceeded {
+		t.Fatal("map:", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, _, raw, err := UnknownXMLTagsReaderMapRawContext(ctx, &slowReader{}, test{})
+	if err != context.DeadlineExceeded {
+		t.Fatal("raw:", err)
+	}
+	if len(raw) < len("<doc><ok>") || !strings.HasPrefix("<doc>"+strings.Repeat("<ok>true</ok>", len(raw)), string(raw)) {
+		t.Fatal("raw:", string(raw))
+	}
+
+	// the traversal is also canceled
+	type item struct {
+		Val string `xml:"val"`
+	}
+	type items struct {
+		Item []item `xml:"item"`
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	m, _ := mxj.NewMapXml([]byte("<doc>" + strings.Repeat("<item><val>1</val></item>", 1000) + "</doc>"))
+	s := tagList{ctx: ctx}
+	checkAllTags(m["doc"], reflect.ValueOf(items{}), &s, "")
+	if s.err != context.Canceled {
+		t.Fatal("traversal:", s.err)
+	}
+
+	data := []byte("<doc><why>test</why><ok>true</ok></doc>")
+	tags, root, err := UnknownXMLTagsReaderContext(context.Background(), bytes.NewReader(data), test{})
+	if err != nil || root != "doc" || len(tags) != 1 || tags[0] != "ok" {
+		t.Fatal("background:", tags, root, err)
+	}
+}
+
 func TestUnknownXMLTagsToIgnore(t *testing.T) {
 	// fmt.Println("===================== TestUnknownXMLTagsToIgnore ...")
 
@@ -239,7 +297,9 @@ func TestUnknownXMLTagsWithSubelemTag(t *testing.T) {
 			<not>I dont't know</not>
 		</doc>`)
 
-	check := map[string]bool{"Why.maybenot": true, "not": true, "Why.-attr": true}
+	// <Why> is the wrapper for the "Why>Maybe" path, so <Maybe> is decoded to
+	// test2 - which has no ",chardata" member for its value.
+	check := map[string]bool{"Why.maybenot": true, "not": true, "Why.-attr": true, "Why.Maybe": true}
 	type test2 struct {
 		Maybe bool `xml:"-"`
 	}
@@ -303,3 +363,657 @@ func TestUnknownTagsSingletonList(t *testing.T) {
 		t.Fatal("didn't report 'zz' for d:", tags)
 	}
 }
+
+func TestUnknownXMLTagsSplit(t *testing.T) {
+	data := []byte(`
+		<doc>
+			<Ok>true</Ok>
+			<Why attr="some val">
+				<Maybe>true</Maybe>
+				<maybenot>false</maybenot>
+			</Why>
+			<not>I dont't know</not>
+		</doc>`)
+
+	type test2 struct {
+		Maybe bool
+	}
+	type test struct {
+		Ok  bool
+		Why test2
+	}
+
+	tv := test{}
+	elems, attrs, root, err := UnknownXMLTagsSplit(data, tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" {
+		t.Fatal("root:", root)
+	}
+	if ok, v := HasTags(elems, "Why.maybenot", "not"); !ok || len(elems) != 2 {
+		t.Fatal("elems:", elems, v)
+	}
+	if len(attrs) != 1 || attrs[0] != "Why.-attr" {
+		t.Fatal("attrs:", attrs)
+	}
+
+	// the tags found before the check is terminated are returned with the error
+	SetMaxResults(2)
+	defer SetMaxResults(0)
+	elems, attrs, _, err = UnknownXMLTagsSplit(data, tv)
+	if err != ErrTruncated {
+		t.Fatal("err:", err)
+	}
+	if len(elems)+len(attrs) != 2 {
+		t.Fatal("truncated:", elems, attrs)
+	}
+}
+
+func TestWalkUnknownXMLTags(t *testing.T) {
+	data := []byte(`<doc><a>1</a><b>2</b><c>3</c><d>4</d></doc>`)
+	type test struct {
+		D string `xml:"d"`
+	}
+
+	var n int
+	root, err := WalkUnknownXMLTags(data, test{}, func(tag string) bool {
+		n++
+		return false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" {
+		t.Fatal("root:", root)
+	}
+	if n != 1 {
+		t.Fatal("walk didn't stop, calls:", n)
+	}
+}
+
+func TestUnknownXMLTagsValues(t *testing.T) {
+	data := []byte(`
+		<doc>
+			<Ok>true</Ok>
+			<Why attr="some val">
+				<Maybe>true</Maybe>
+				<maybenot>false</maybenot>
+			</Why>
+			<not><sub>I dont't know</sub></not>
+		</doc>`)
+
+	type test2 struct {
+		Maybe bool
+	}
+	type test struct {
+		Ok  bool
+		Why test2
+	}
+
+	tv := test{}
+	vals, root, err := UnknownXMLTagsValues(data, tv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vals) != 3 {
+		t.Fatal("vals:", vals)
+	}
+	m, err := mxj.NewMapXml(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vals {
+		mv, err := m.ValuesForPath(root + "." + v.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(mv) != 1 || !reflect.DeepEqual(mv[0], v.Value) {
+			t.Fatalf("%s: %v != %v", v.Path, v.Value, mv)
+		}
+	}
+}
+
+func TestUnknownXMLTagsDetailed(t *testing.T) {
+	data := []byte(`
+		<doc>
+			<Ok>true</Ok>
+			<Why attr="some val">
+				<Maybe>true</Maybe>
+				<maybenot>false</maybenot>
+			</Why>
+			<not>I dont't know</not>
+		</doc>`)
+
+	type test2 struct {
+		Maybe bool
+	}
+	type test struct {
+		Ok  bool
+		Why test2
+	}
+
+	tags, _, err := UnknownXMLTagsDetailed(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := map[string]string{"Why.maybenot": "test2", "Why.-attr": "test2", "not": "test"}
+	if len(tags) != len(check) {
+		t.Fatal("tags:", tags)
+	}
+	for _, v := range tags {
+		if check[v.Path] != v.Type {
+			t.Fatalf("%s: type %q, expected %q", v.Path, v.Type, check[v.Path])
+		}
+	}
+}
+
+func TestHasUnknownAttrs(t *testing.T) {
+	type test2 struct {
+		Maybe bool
+	}
+	type test struct {
+		Ok  bool
+		Why test2
+	}
+
+	data := []byte(`<doc><Ok>true</Ok><not>x</not><Why attr="some val"><Maybe>true</Maybe></Why></doc>`)
+	ok, err := HasUnknownAttrs(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatal("didn't find Why.-attr")
+	}
+
+	data = []byte(`<doc><Ok>true</Ok><not>x</not><Why><Maybe>true</Maybe></Why></doc>`)
+	ok, err = HasUnknownAttrs(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("found an unknown attribute")
+	}
+}
+
+func TestReportUnexportedAsKnown(t *testing.T) {
+	type test struct {
+		Name   string `xml:"name"`
+		secret string
+	}
+	data := []byte(`<doc><name>a name</name><secret>hush</secret><other>x</other></doc>`)
+
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameTags(tags, []string{"secret", "other"}) {
+		t.Fatal("tags:", tags)
+	}
+
+	ReportUnexportedAsKnown(true)
+	defer ReportUnexportedAsKnown(false)
+
+	tags, _, err = UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "other" {
+		t.Fatal("tags:", tags)
+	}
+
+	tags, _, err = UnexportedXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "secret" {
+		t.Fatal("unexported:", tags)
+	}
+}
+
+func TestUnknownXMLTagsType(t *testing.T) {
+	type test struct {
+		Ok bool `xml:"ok"`
+	}
+	data := []byte(`<doc><ok>true</ok><why>not</why></doc>`)
+
+	tags, root, err := UnknownXMLTagsType(data, reflect.TypeOf(test{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(tags) != 1 || tags[0] != "why" {
+		t.Fatal("tags:", root, tags)
+	}
+}
+
+func TestIgnoreAllAttrs(t *testing.T) {
+	type why struct {
+		Lang  string `xml:"lang,attr"`
+		Maybe bool   `xml:"maybe"`
+	}
+	type test struct {
+		ID  string `xml:"id,attr"`
+		Ok  bool   `xml:"ok"`
+		Why why    `xml:"why"`
+	}
+	data := []byte(`<doc vendor="x" ts="1"><ok flag="y">true</ok><why a="1" b="2"><maybe c="3">true</maybe><not/></why></doc>`)
+
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 5 {
+		t.Fatal("unknown:", tags)
+	}
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 2 {
+		t.Fatal("missing:", mems)
+	}
+
+	IgnoreAllAttrs(true)
+	defer IgnoreAllAttrs(false)
+
+	tags, _, err = UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "why.not" {
+		t.Fatal("unknown:", tags)
+	}
+	mems, _, err = MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	if ok, _ := HasUnknownAttrs(data, test{}); ok {
+		t.Fatal("HasUnknownAttrs")
+	}
+}
+
+func TestMatchAttrOrElement(t *testing.T) {
+	type item struct {
+		ID   string `xml:"id"`        // sent as an attribute
+		Lang string `xml:"lang,attr"` // sent as an element
+		Name string `xml:"name"`
+	}
+	type test struct {
+		Item item `xml:"item"`
+	}
+	data := []byte(`<doc><item id="1"><lang>en</lang><name>x</name></item></doc>`)
+
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mems, []string{"item.id", "item.-lang"}) {
+		t.Fatal("missing:", mems)
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(tags)
+	if !reflect.DeepEqual(tags, []string{"item.-id", "item.lang"}) {
+		t.Fatal("unknown:", tags)
+	}
+
+	MatchAttrOrElement(true)
+	defer MatchAttrOrElement(false)
+	if mems, _, _ = MissingXMLTags(data, test{}); len(mems) != 0 {
+		t.Fatal("missing, matched:", mems)
+	}
+	if tags, _, _ = UnknownXMLTags(data, test{}); len(tags) != 0 {
+		t.Fatal("unknown, matched:", tags)
+	}
+
+	// the data of a member's own kind is preferred; the other is still unknown
+	data = []byte(`<doc><item id="1" lang="en"><id>2</id><lang>fr</lang><name>x</name></item></doc>`)
+	if mems, _, _ = MissingXMLTags(data, test{}); len(mems) != 0 {
+		t.Fatal("missing, both:", mems)
+	}
+	tags, _, _ = UnknownXMLTags(data, test{})
+	sort.Strings(tags)
+	if !reflect.DeepEqual(tags, []string{"item.-id", "item.lang"}) {
+		t.Fatal("unknown, both:", tags)
+	}
+	MatchAttrOrElement(false)
+
+	v := NewValidator(reflect.TypeOf(test{}), WithMatchAttrOrElement(true))
+	data = []byte(`<doc><item id="1"><lang>en</lang><name>x</name></item></doc>`)
+	if mems, tags, _, err = v.Validate(data); err != nil || len(mems) != 0 || len(tags) != 0 {
+		t.Fatal("validator:", mems, tags, err)
+	}
+}
+
+func TestUnknownXMLTagsWrapperPath(t *testing.T) {
+	data := []byte(`<doc><title>x</title><meta><author>me</author><date>today</date><extra/></meta><meta><author>you</author></meta></doc>`)
+
+	type test struct {
+		Title  string `xml:"title"`
+		Author string `xml:"meta>author"`
+		Date   string `xml:"meta>date"`
+	}
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "meta.extra" {
+		t.Fatal("unknown:", tags)
+	}
+
+	// nested wrappers
+	type test2 struct {
+		Author string `xml:"meta>info>author"`
+		Date   string `xml:"meta>date"`
+	}
+	tags, _, err = UnknownXMLTags([]byte(`<doc><meta><info><author>me</author><x/></info><date/></meta></doc>`), test2{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "meta.info.x" {
+		t.Fatal("unknown:", tags)
+	}
+	if tags := CheckStructTags(test2{}); len(tags) != 0 {
+		t.Fatal("duplicates:", tags)
+	}
+}
+
+func TestUnknownXMLTagsGrouped(t *testing.T) {
+	type address struct {
+		Street string `xml:"street"`
+	}
+	type test struct {
+		Name    string  `xml:"name"`
+		Address address `xml:"address"`
+	}
+	data := []byte(`<doc><name>me</name><age>1</age>
+		<address><street>x</street><city/><zip/><country/><phone/><fax/></address>
+		<contact><email/></contact></doc>`)
+
+	groups, root, err := UnknownXMLTagsGrouped(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(groups) != 2 {
+		t.Fatal("groups:", root, groups)
+	}
+	if g := groups[""]; len(g) != 2 || g[0] != "age" || g[1] != "contact" {
+		t.Fatal("root group:", g)
+	}
+	if g := groups["address"]; len(g) != 5 || g[0] != "city" || g[4] != "zip" {
+		t.Fatal("address group:", g)
+	}
+
+	groups, _, err = UnknownXMLTagsGrouped([]byte(`<doc><name>me</name></doc>`), test{})
+	if err != nil || groups != nil {
+		t.Fatal("none:", groups, err)
+	}
+}
+
+func TestNamespaceDeclarations(t *testing.T) {
+	type test struct {
+		ID string `xml:"urn:example id"`
+	}
+	data := []byte(`<doc xmlns="urn:example"><id>1</id></doc>`)
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+	// a prefixed declaration, "-x", isn't data for either decoder
+	data = []byte(`<x:doc xmlns:x="urn:example"><x:id>1</x:id></x:doc>`)
+	tags, _, err = UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown:", tags)
+	}
+	tags, _, err = UnknownXMLTagsStd(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("unknown std:", tags)
+	}
+}
+
+func TestNamespaceDeclarationsPrefixed(t *testing.T) {
+	type item struct {
+		Name string `xml:"name"`
+		Code string `xml:"code,attr"`
+	}
+	type test struct {
+		ID   string `xml:"id"`
+		Item item   `xml:"item"`
+	}
+	// declarations on the root and on a child element; "x" is an attribute
+	data := []byte(`<a:doc xmlns:a="urn:a"><a:id>1</a:id><item xmlns:b="urn:b" b:code="c" x="y"><b:name>n</b:name></item></a:doc>`)
+	want := []string{"item.-x"}
+
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("unknown:", tags)
+	}
+	tags, _, err = UnknownXMLTagsStd(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("unknown std:", tags)
+	}
+	tags, _, err = UnknownXMLTagsReader(bytes.NewReader(data), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("unknown reader:", tags)
+	}
+	err = UnknownXMLTagsStream(bytes.NewReader(data), test{}, func(_ string, unknown []string) {
+		tags = unknown
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatal("unknown stream:", tags)
+	}
+
+	// the declarations follow the attribute prefix
+	mxj.SetAttrPrefix("@")
+	SetAttrPrefix("@")
+	defer func() {
+		mxj.SetAttrPrefix("-")
+		SetAttrPrefix("-")
+	}()
+	tags, _, _ = UnknownXMLTags(data, test{})
+	if len(tags) != 1 || tags[0] != "item.@x" {
+		t.Fatal("prefix:", tags)
+	}
+}
+
+func TestUnknownXMLTagsCanonical(t *testing.T) {
+	type sub struct {
+		ID    string `xml:"id,attr"`
+		Value string `xml:"value"`
+	}
+	type test struct {
+		Name string `xml:"name"`
+		Sub  sub    `xml:"sub"`
+	}
+	data := []byte(`<doc><Name>x</Name><sub ID="1"><VALUE>2</VALUE><value>3</value><other/></sub></doc>`)
+
+	tags, root, err := UnknownXMLTagsCanonical(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Path < tags[j].Path })
+	want := []CanonicalTag{
+		{"Name", "name"},
+		{"sub.-ID", "sub.-id"},
+		{"sub.VALUE", "sub.value"},
+		{"sub.other", ""},
+	}
+	if root != "doc" || !reflect.DeepEqual(tags, want) {
+		t.Fatal("canonical:", root, tags)
+	}
+}
+
+func TestScalarRoot(t *testing.T) {
+	type test struct {
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	for _, data := range []string{`<doc>just text</doc>`, `<doc/>`} {
+		tags, root, err := UnknownXMLTags([]byte(data), test{})
+		if err != nil || tags != nil || root != "doc" {
+			t.Fatal("unknown:", data, tags, root, err)
+		}
+		if tags, _, err = UnknownXMLTagsStd([]byte(data), test{}); err != nil || tags != nil {
+			t.Fatal("unknown std:", data, tags, err)
+		}
+		if _, err = WalkUnknownXMLTags([]byte(data), test{}, func(string) bool { return true }); err != nil {
+			t.Fatal("walk:", data, err)
+		}
+		mems, root, err := MissingXMLTags([]byte(data), test{})
+		if err != nil || len(mems) != 1 || mems[0] != "test" || root != "doc" {
+			t.Fatal("missing:", data, mems, root, err)
+		}
+	}
+}
+
+// rawXML is a named []byte type, as json.RawMessage is for JSON.
+type rawXML []byte
+
+func TestByteSliceContent(t *testing.T) {
+	type test struct {
+		Raw   []byte   `xml:"raw"`
+		Named rawXML   `xml:"named"`
+		Ptr   *[]byte  `xml:"ptr"`
+		List  [][]byte `xml:"list"`
+		Name  string   `xml:"name"`
+	}
+	data := []byte(`<doc>
+		<raw a="1"><x>1</x><y><z/></y></raw>
+		<named><x/></named>
+		<ptr><x><y/></x></ptr>
+		<list><x/></list><list b="2"><y/></list>
+		<name>n</name>
+		<other/>
+	</doc>`)
+
+	tags, _, err := UnknownXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0] != "other" {
+		t.Fatal("unknown:", tags)
+	}
+	if tags, _, _ = UnknownXMLTagsStd(data, test{}); len(tags) != 1 {
+		t.Fatal("std unknown:", tags)
+	}
+	mems, _, err := MissingXMLTags(data, test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 0 {
+		t.Fatal("missing:", mems)
+	}
+	if ok, _ := IsValid(data, test{}); ok {
+		t.Fatal("valid with <other>")
+	}
+}
+
+func TestUnknownXMLTagsStrict(t *testing.T) {
+	type test struct {
+		Name string `xml:"name"`
+	}
+	root, err := UnknownXMLTagsStrict([]byte(`<doc><name>n</name></doc>`), test{})
+	if err != nil || root != "doc" {
+		t.Fatal(root, err)
+	}
+
+	_, err = UnknownXMLTagsStrict([]byte(`<doc><name>n</name><qty> 12 </qty></doc>`), test{})
+	var ute *UnknownTagError
+	if !errors.As(err, &ute) {
+		t.Fatal("error:", err)
+	}
+	if ute.Tag != "qty" || ute.Value != `"12"` || ute.Count != 1 {
+		t.Fatalf("%+v", ute)
+	}
+	if err.Error() != `unknown tag "qty" with value "12"` {
+		t.Fatal(err)
+	}
+
+	long := strings.Repeat("x", UnknownValueLen+10)
+	_, err = UnknownXMLTagsStrict([]byte(`<doc><note>`+long+`</note></doc>`), test{})
+	if want := strconv.Quote(long[:UnknownValueLen] + "..."); !strings.Contains(err.Error(), want) {
+		t.Fatal("truncated:", err)
+	}
+
+	OrderByDocument(true)
+	defer OrderByDocument(false)
+	_, err = UnknownXMLTagsStrict([]byte(`<doc><extra a="1">x</extra><list/><list/><n>5</n></doc>`), test{})
+	if err == nil || err.Error() != `unknown tag "extra" with value (complex element) (and 2 more)` {
+		t.Fatal("complex:", err)
+	}
+	SetMxjCast(true)
+	defer SetMxjCast(false)
+	_, err = UnknownXMLTagsStrict([]byte(`<doc><list/><list/><n>5</n></doc>`), test{})
+	if err == nil || err.Error() != `unknown tag "list" with value (repeated element) (and 1 more)` {
+		t.Fatal("repeated:", err)
+	}
+	_, err = UnknownXMLTagsStrict([]byte(`<doc><n>5</n></doc>`), test{})
+	if err == nil || err.Error() != `unknown tag "n" with value 5` {
+		t.Fatal("cast:", err)
+	}
+
+	if _, err = UnknownXMLTagsStrict([]byte(`<doc>`), test{}); !errors.As(err, new(*ParseError)) {
+		t.Fatal("parse error:", err)
+	}
+}
+
+// Whitespace around an element name can't make an unknown tag: the decoder drops
+// it after the name, <name >, and rejects it before the name, < name>.  A trailing
+// space in a struct tag, `xml:"name "`, makes "name" the namespace of the member,
+// as it does for encoding/xml, so the member is <Name>.
+func TestWhitespaceInTags(t *testing.T) {
+	type test struct {
+		Name string `xml:"name"`
+	}
+	tags, _, err := UnknownXMLTags([]byte(`<doc><name >x</name ></doc>`), test{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 0 {
+		t.Fatal("trailing:", tags)
+	}
+	for _, data := range []string{
+		"<doc>< name>x</name></doc>",
+		"<doc><name >x</name ></doc>",
+		"<doc><name　>x</name　></doc>",
+	} {
+		if _, _, err = UnknownXMLTags([]byte(data), test{}); !errors.As(err, new(*ParseError)) {
+			t.Fatalf("%q: %v", data, err)
+		}
+	}
+
+	// go vet reports the space in a source struct tag, so the struct is built
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "Name", Type: reflect.TypeOf(""), Tag: `xml:"name "`},
+	})
+	mems, _, _ := MissingXMLTags([]byte(`<doc><name>x</name></doc>`), reflect.New(typ).Interface())
+	if len(mems) != 1 || mems[0] != "Name" {
+		t.Fatal("namespace:", mems)
+	}
+}
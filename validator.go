@@ -0,0 +1,83 @@
+// validator.go - check XML data against a struct type with its own settings
+// Copyright © 2017-2019 Charles Banning.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package checkxml
+
+import (
+	"reflect"
+)
+
+// Validator checks XML data against a struct type using its own settings rather
+// than the package settings.  The struct member tags are parsed when the Validator
+// is created, and a Validator can be used by multiple goroutines concurrently.
+//
+//	Example:
+//		v := NewValidator(reflect.TypeOf(MyStruct{}), WithTagsToIgnore("config"))
+//		for _, b := range docs {
+//			missing, unknown, root, err := v.Validate(b)
+//			...
+//		}
+type Validator struct {
+	typ  reflect.Type
+	val  interface{} // zero value of typ
+	opts *options
+}
+
+// NewValidator returns a Validator for the struct type 't' - e.g.,
+// reflect.TypeOf(MyStruct{}); if 't' is a pointer type, the struct it points to is
+// used.  The Validator starts with a copy of the current package settings - see
+// SetTagsToIgnore, IgnoreOmitemptyTag, etc. - which are then modified by 'opts'.
+// Later calls to the package setter functions don't affect the Validator.
+func NewValidator(t reflect.Type, opts ...Option) *Validator {
+	v := &Validator{typ: t, val: zeroValue(t), opts: currentOptions()}
+	for _, opt := range opts {
+		opt(v.opts)
+	}
+	loadTypeSpecs(t, v.opts.attrPrefix, make(map[reflect.Type]bool))
+	return v
+}
+
+// Type returns the struct type that the Validator checks XML data against.
+func (v *Validator) Type() reflect.Type {
+	return v.typ
+}
+
+// Missing is MissingXMLTags using the Validator's struct type and settings.
+func (v *Validator) Missing(b []byte) ([]string, string, error) {
+	s, root, err := missingXMLTags(b, v.val, v.opts)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.tags, root, s.err
+}
+
+// Unknown is UnknownXMLTags using the Validator's struct type and settings.
+func (v *Validator) Unknown(b []byte) ([]string, string, error) {
+	return unknownXMLTags(b, v.val, v.opts)
+}
+
+// Validate returns the results of both Missing and Unknown, decoding the XML
+// data only once.  As for Missing and Unknown, the tags found before a check is
+// terminated with an error, e.g., ErrTruncated, are returned with it.
+func (v *Validator) Validate(b []byte) (missing, unknown []string, root string, err error) {
+	root, _, m, u, err := checkXMLTags(b, v.val, v.opts)
+	if m == nil {
+		return nil, nil, root, err
+	}
+	return m.tags, u.tags, root, err
+}
+
+// loadTypeSpecs parses the member tags of 'typ' and of the struct types of its
+// members into the typeSpec cache.
+func loadTypeSpecs(typ reflect.Type, prefix string, visited map[reflect.Type]bool) {
+	typ = memberType(typ)
+	if typ.Kind() != reflect.Struct || visited[typ] || isLeafType(typ) {
+		return
+	}
+	visited[typ] = true
+	for _, f := range getTypeSpec(typ, prefix).fields {
+		loadTypeSpecs(typ.FieldByIndex(f.index).Type, prefix, visited)
+	}
+}
@@ -0,0 +1,119 @@
+package checkxml
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestValidator(t *testing.T) {
+	type sub struct {
+		Subelem string `xml:"subelem,omitempty"`
+		Another string `xml:"another"`
+	}
+	type elem struct {
+		Elem1 string `xml:"elem1"`
+		Elem2 sub    `xml:"elem2"`
+		Elem3 bool   `xml:"elem3"`
+	}
+	data := []byte(`<doc>
+	           <elem1>a simple element</elem1>
+	           <elem2>
+	             <subelem>something more complex</subelem>
+	             <notes>take a look at this</notes>
+	           </elem2>
+	           <elem4>extraneous</elem4>
+	         </doc>`)
+
+	v := NewValidator(reflect.TypeOf(&elem{}))
+	if v.Type() != reflect.TypeOf(&elem{}) {
+		t.Fatal("type:", v.Type())
+	}
+	missing, root, err := v.Missing(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(missing) != 2 || missing[0] != "elem2.another" || missing[1] != "elem3" {
+		t.Fatal("missing:", root, missing)
+	}
+	unknown, _, err := v.Unknown(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(unknown)
+	if len(unknown) != 2 || unknown[0] != "elem2.notes" || unknown[1] != "elem4" {
+		t.Fatal("unknown:", unknown)
+	}
+	missing, unknown, root, err = v.Validate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != "doc" || len(missing) != 2 || len(unknown) != 2 {
+		t.Fatal("validate:", root, missing, unknown)
+	}
+
+	// the Validator settings are its own
+	v = NewValidator(reflect.TypeOf(elem{}),
+		WithTagsToIgnore("elem4"),
+		WithMembersToIgnore("elem3"),
+		WithOmitemptyTag(false))
+	SetTagsToIgnore("elem2.notes")
+	defer SetTagsToIgnore()
+
+	missing, unknown, _, err = v.Validate(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "elem2.another" {
+		t.Fatal("missing:", missing)
+	}
+	if len(unknown) != 1 || unknown[0] != "elem2.notes" {
+		t.Fatal("unknown:", unknown)
+	}
+	unknown, _, _ = UnknownXMLTags(data, elem{})
+	if len(unknown) != 1 || unknown[0] != "elem4" {
+		t.Fatal("package unknown:", unknown)
+	}
+
+	if _, _, _, err = v.Validate([]byte(`<doc><elem1>`)); err == nil {
+		t.Fatal("no error")
+	}
+}
+
+func TestValidatorConcurrent(t *testing.T) {
+	type test struct {
+		ID  string `xml:"id,attr"`
+		Ok  bool   `xml:"ok"`
+		Why string `xml:"why"`
+	}
+	data := []byte(`<doc id="1"><ok>true</ok><not/></doc>`)
+
+	a := NewValidator(reflect.TypeOf(test{}))
+	b := NewValidator(reflect.TypeOf(test{}), WithMembersToIgnore("why"), WithTagsToIgnore("not"))
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 100)
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			m, u, _, _ := a.Validate(data)
+			if len(m) != 1 || len(u) != 1 {
+				errs <- "a"
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			m, u, _, _ := b.Validate(data)
+			if len(m) != 0 || len(u) != 0 {
+				errs <- "b"
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Fatal("validator:", e)
+	}
+}